@@ -3,6 +3,10 @@ import * as aws from "@pulumi/aws";
 
 const config = new pulumi.Config();
 
+const discordBotTokenSecretArn = config.get("discord-bot-token-secret-arn");
+const mongodbUriSecretArn = config.get("mongodb-uri-secret-arn");
+const secretArns = [discordBotTokenSecretArn, mongodbUriSecretArn].filter((arn): arn is string => !!arn);
+
 const lambdaRole = new aws.iam.Role("feednyang-lambda-role", {
   assumeRolePolicy: JSON.stringify({
     Version: "2012-10-17",
@@ -23,6 +27,39 @@ new aws.iam.RolePolicyAttachment("feednyang-lambda-basic-policy", {
   policyArn: "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
 });
 
+if (secretArns.length > 0) {
+  new aws.iam.RolePolicy("feednyang-lambda-secrets-policy", {
+    role: lambdaRole.id,
+    policy: JSON.stringify({
+      Version: "2012-10-17",
+      Statement: [
+        {
+          Effect: "Allow",
+          Action: "secretsmanager:GetSecretValue",
+          Resource: secretArns
+        }
+      ]
+    })
+  });
+}
+
+// export-all 이벤트로 discord_channels 전체를 NDJSON 으로 백업하고, import-all 로 되살리는 버킷
+const backupBucket = new aws.s3.Bucket("feednyang-backups", {});
+
+new aws.iam.RolePolicy("feednyang-lambda-backup-policy", {
+  role: lambdaRole.id,
+  policy: backupBucket.arn.apply(arn => JSON.stringify({
+    Version: "2012-10-17",
+    Statement: [
+      {
+        Effect: "Allow",
+        Action: ["s3:PutObject", "s3:AbortMultipartUpload", "s3:GetObject"],
+        Resource: `${arn}/*`
+      }
+    ]
+  }))
+});
+
 const feednyangRssFeedFunc = new aws.lambda.Function("feednyang-rss-feed", {
   code: new pulumi.asset.AssetArchive({
     ".": new pulumi.asset.FileArchive("./lambda/feednyang-rss-feed"),
@@ -32,9 +69,28 @@ const feednyangRssFeedFunc = new aws.lambda.Function("feednyang-rss-feed", {
   role: lambdaRole.arn,
   environment: {
     variables: {
-      DISCORD_BOT_TOKEN: config.require("discord-bot-token"),
+      ...(discordBotTokenSecretArn
+        ? { DISCORD_BOT_TOKEN_SECRET_ARN: discordBotTokenSecretArn }
+        : { DISCORD_BOT_TOKEN: config.require("discord-bot-token") }),
       DEFAULT_DISCORD_CHANNEL_IDS: config.require("default-discord-channel-ids"),
-      MONGODB_URI: config.require("mongodb-uri")
+      FEED_SEND_ORDER: config.get("feed-send-order") ?? "insertion",
+      FEED_USER_AGENT: config.get("feed-user-agent") ?? "",
+      FEED_GRACE_WINDOW: config.get("feed-grace-window") ?? "10m",
+      INITIAL_CATCHUP_WINDOW: config.get("initial-catchup-window") ?? "48h",
+      FEED_FORMAT_CHANGE_THRESHOLD: config.get("feed-format-change-threshold") ?? "10",
+      FEED_WORKERS: config.get("feed-workers") ?? "",
+      CHANNEL_WORKERS: config.get("channel-workers") ?? "",
+      CHANNEL_SEND_CONCURRENCY: config.get("channel-send-concurrency") ?? "1",
+      MAX_SENDS_PER_RUN: config.get("max-sends-per-run") ?? "200",
+      FEED_RETRY_ATTEMPTS: config.get("feed-retry-attempts") ?? "3",
+      FEED_RETRY_BASE_DELAY: config.get("feed-retry-base-delay") ?? "2s",
+      ALLOW_PRIVATE_FEED_TARGETS: config.get("allow-private-feed-targets") ?? "false",
+      ALLOW_INSECURE_TLS: config.get("allow-insecure-tls") ?? "false",
+      OPERATOR_CHANNEL_ID: config.get("operator-channel-id") ?? "",
+      BACKUP_BUCKET: backupBucket.bucket,
+      ...(mongodbUriSecretArn
+        ? { MONGODB_URI_SECRET_ARN: mongodbUriSecretArn }
+        : { MONGODB_URI: config.require("mongodb-uri") })
     }
   },
   timeout: 300
@@ -49,8 +105,21 @@ const feednyangCommandFunc = new aws.lambda.Function("feednyang-command", {
   role: lambdaRole.arn,
   environment: {
     variables: {
-      MONGODB_URI: config.require("mongodb-uri"),
-      DISCORD_PUBLIC_KEY: config.require("discord-public-key")
+      ...(mongodbUriSecretArn
+        ? { MONGODB_URI_SECRET_ARN: mongodbUriSecretArn }
+        : { MONGODB_URI: config.require("mongodb-uri") }),
+      ...(discordBotTokenSecretArn
+        ? { DISCORD_BOT_TOKEN_SECRET_ARN: discordBotTokenSecretArn }
+        : { DISCORD_BOT_TOKEN: config.require("discord-bot-token") }),
+      DISCORD_PUBLIC_KEY: config.require("discord-public-key"),
+      OWNER_USER_IDS: config.get("owner-user-ids") ?? "",
+      READONLY_MODE: config.get("readonly-mode") ?? "false",
+      RANK_COMMAND_ENABLED: config.get("rank-command-enabled") ?? "false",
+      EXPENSIVE_COMMAND_COOLDOWN: config.get("expensive-command-cooldown") ?? "10s",
+      MAX_FEEDS_PER_CHANNEL: config.get("max-feeds-per-channel") ?? "50",
+      FEED_USER_AGENT: config.get("feed-user-agent") ?? "",
+      ALLOW_PRIVATE_FEED_TARGETS: config.get("allow-private-feed-targets") ?? "false",
+      ALLOW_INSECURE_TLS: config.get("allow-insecure-tls") ?? "false"
     }
   },
   timeout: 30
@@ -137,5 +206,6 @@ new aws.cloudwatch.EventTarget("lambda-target-saturday", {
 export const feednyangRssFeedArn = feednyangRssFeedFunc.arn;
 export const feednyangCommandArn = feednyangCommandFunc.arn;
 export const feednyangCommandUrl = feednyangCommandFuncUrl.functionUrl;
+export const backupBucketName = backupBucket.bucket;
 export const weekdayScheduleRuleArn = weekdayScheduleRule.arn;
 export const saturdayScheduleRuleArn = saturdayScheduleRule.arn;
@@ -1,54 +1,122 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/ed25519"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
 	"log"
+	"mime"
+	"net"
 	"net/http"
+	neturl "net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 type Feed struct {
-	BlogName       string    `bson:"blogName" json:"blogName"`
-	RssURL         string    `bson:"rssUrl" json:"rssUrl"`
-	AddedAt        time.Time `bson:"addedAt" json:"addedAt"`
-	LastSentTime   time.Time `bson:"lastSentTime" json:"lastSentTime"`
-	LastPostLink   string    `bson:"lastPostLink" json:"lastPostLink"`
-	TotalPostsSent int       `bson:"totalPostsSent" json:"totalPostsSent"`
+	BlogName            string    `bson:"blogName" json:"blogName"`
+	RssURL              string    `bson:"rssUrl" json:"rssUrl"`
+	AddedAt             time.Time `bson:"addedAt" json:"addedAt"`
+	LastSentTime        time.Time `bson:"lastSentTime" json:"lastSentTime"`
+	LastPostLink        string    `bson:"lastPostLink" json:"lastPostLink"`
+	LastPostTitle       string    `bson:"lastPostTitle,omitempty" json:"lastPostTitle,omitempty"`
+	TotalPostsSent      int       `bson:"totalPostsSent" json:"totalPostsSent"`
+	Note                string    `bson:"note,omitempty" json:"note,omitempty"`
+	ConsecutiveFailures int       `bson:"consecutiveFailures" json:"consecutiveFailures"`
+	BlockKeywords       []string  `bson:"blockKeywords,omitempty" json:"blockKeywords,omitempty"`
+	UserAgent           string    `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	ThreadID            string    `bson:"threadId,omitempty" json:"threadId,omitempty"`
+	LastError           string    `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	MirrorChannelIDs    []string  `bson:"mirrorChannelIds,omitempty" json:"mirrorChannelIds,omitempty"`
+	SiteURL             string    `bson:"siteUrl,omitempty" json:"siteUrl,omitempty"`
+	IconURL             string    `bson:"iconUrl,omitempty" json:"iconUrl,omitempty"`
+	ShortID             string    `bson:"shortId,omitempty" json:"shortId,omitempty"`
+	PollInterval        int       `bson:"pollInterval,omitempty" json:"pollInterval,omitempty"`
+	LastCheckedAt       time.Time `bson:"lastCheckedAt,omitempty" json:"lastCheckedAt,omitempty"`
+	ActiveHoursStart    int       `bson:"activeHoursStart,omitempty" json:"activeHoursStart,omitempty"`
+	ActiveHoursEnd      int       `bson:"activeHoursEnd,omitempty" json:"activeHoursEnd,omitempty"`
+	AvgParseMs          int64     `bson:"avgParseMs,omitempty" json:"avgParseMs,omitempty"`
+	AvgDeliverySeconds  int64     `bson:"avgDeliverySeconds,omitempty" json:"avgDeliverySeconds,omitempty"`
+	LastPostUpdatedAt   time.Time `bson:"lastPostUpdatedAt,omitempty" json:"lastPostUpdatedAt,omitempty"`
+	OverrideChannelID   string    `bson:"overrideChannelId,omitempty" json:"overrideChannelId,omitempty"`
+	IntroSent           bool      `bson:"introSent,omitempty" json:"introSent,omitempty"`
+	IncludeContent      bool      `bson:"includeContent,omitempty" json:"includeContent,omitempty"`
+	AddedBy             string    `bson:"addedBy,omitempty" json:"addedBy,omitempty"`
+	MinItemAge          int       `bson:"minItemAge,omitempty" json:"minItemAge,omitempty"`
+	RequireCategories   []string  `bson:"requireCategories,omitempty" json:"requireCategories,omitempty"`
+	Keywords            []string  `bson:"keywords,omitempty" json:"keywords,omitempty"`
+	Paused              bool      `bson:"paused,omitempty" json:"paused,omitempty"`
 }
 
 type DiscordChannel struct {
-	ID        string    `bson:"_id" json:"_id"`
-	Feeds     []Feed    `bson:"feeds" json:"feeds"`
-	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+	ID                string    `bson:"_id" json:"_id"`
+	Feeds             []Feed    `bson:"feeds" json:"feeds"`
+	DeliveryMode      string    `bson:"deliveryMode,omitempty" json:"deliveryMode,omitempty"`
+	ThreadMode        bool      `bson:"threadMode,omitempty" json:"threadMode,omitempty"`
+	DisplayFields     []string  `bson:"displayFields,omitempty" json:"displayFields,omitempty"`
+	MinPostInterval   int       `bson:"minPostInterval,omitempty" json:"minPostInterval,omitempty"`
+	BurstThreshold    int       `bson:"burstThreshold,omitempty" json:"burstThreshold,omitempty"`
+	LanguageFilter    string    `bson:"languageFilter,omitempty" json:"languageFilter,omitempty"`
+	SuppressEmbeds    bool      `bson:"suppressEmbeds,omitempty" json:"suppressEmbeds,omitempty"`
+	UpdateNotices     bool      `bson:"updateNotices,omitempty" json:"updateNotices,omitempty"`
+	FollowsDefaults   bool      `bson:"followsDefaults,omitempty" json:"followsDefaults,omitempty"`
+	LastChannelPostAt time.Time `bson:"lastChannelPostAt,omitempty" json:"lastChannelPostAt,omitempty"`
+	LastExpensiveOpAt time.Time `bson:"lastExpensiveOpAt,omitempty" json:"lastExpensiveOpAt,omitempty"`
+	CreatedBy         string    `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt         time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
 type DiscordInteraction struct {
 	Type int                    `json:"type"`
 	Data DiscordInteractionData `json:"data"`
 	ID   string                 `json:"id"`
-	User struct {
-		ID       string `json:"id"`
-		Username string `json:"username"`
-	} `json:"user"`
+	User DiscordUser            `json:"user"`
+	// 서버 채널에서 호출하면 user 대신 member.user 에 사용자 정보가 담긴다
+	Member struct {
+		User        DiscordUser `json:"user"`
+		Permissions string      `json:"permissions"`
+	} `json:"member"`
 	ChannelID string `json:"channel_id"`
 	GuildID   string `json:"guild_id"`
 	Token     string `json:"token"`
+	// 호출된 채널에서 봇이 가진 권한. DM 처럼 권한 개념이 없는 곳에서는 비어 있다
+	AppPermissions string `json:"app_permissions"`
+}
+
+type DiscordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
 }
 
 type DiscordInteractionData struct {
@@ -56,12 +124,29 @@ type DiscordInteractionData struct {
 	Name    string                         `json:"name"`
 	Type    int                            `json:"type"`
 	Options []DiscordInteractionDataOption `json:"options"`
+	// 버튼 같은 메시지 컴포넌트를 눌렀을 때 채워진다
+	CustomID      string `json:"custom_id"`
+	ComponentType int    `json:"component_type"`
+	// 메시지 컨텍스트 메뉴 명령어에서 우클릭한 메시지와 그 내용이 채워진다
+	TargetID string                     `json:"target_id"`
+	Resolved DiscordInteractionResolved `json:"resolved"`
+}
+
+type DiscordInteractionResolved struct {
+	Messages map[string]DiscordResolvedMessage `json:"messages"`
+}
+
+type DiscordResolvedMessage struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
 }
 
 type DiscordInteractionDataOption struct {
 	Name  string `json:"name"`
 	Type  int    `json:"type"`
 	Value any    `json:"value"`
+	// 자동 완성 요청에서 사용자가 지금 입력하고 있는 옵션이면 true 다
+	Focused bool `json:"focused"`
 }
 
 type DiscordInteractionResponse struct {
@@ -69,356 +154,5053 @@ type DiscordInteractionResponse struct {
 	Data DiscordInteractionResponseData `json:"data"`
 }
 
+// 후보가 없어도 choices 는 빈 배열로 보내야 한다.
+type DiscordAutocompleteResponse struct {
+	Type int `json:"type"`
+	Data struct {
+		Choices []DiscordAutocompleteChoice `json:"choices"`
+	} `json:"data"`
+}
+
+type DiscordAutocompleteChoice struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type DiscordInteractionResponseData struct {
-	Content string `json:"content"`
-	Flags   int    `json:"flags,omitempty"`
+	Content string         `json:"content"`
+	Flags   int            `json:"flags,omitempty"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+	// 버튼을 누른 뒤에도 버튼을 비활성화한 채로 남겨두므로 빈 목록을 보낼 일은 없다
+	Components []DiscordComponent `json:"components,omitempty"`
+	// 사용자 멘션을 이름으로만 보여주고 알림은 보내지 않을 때 쓴다
+	AllowedMentions *DiscordAllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+type DiscordComponent struct {
+	Type       int                `json:"type"`
+	Style      int                `json:"style,omitempty"`
+	Label      string             `json:"label,omitempty"`
+	CustomID   string             `json:"custom_id,omitempty"`
+	Disabled   bool               `json:"disabled,omitempty"`
+	Components []DiscordComponent `json:"components,omitempty"`
+}
+
+type DiscordEmbed struct {
+	Title       string                 `json:"title,omitempty"`
+	URL         string                 `json:"url,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Thumbnail   *DiscordEmbedThumbnail `json:"thumbnail,omitempty"`
+}
+
+type DiscordEmbedThumbnail struct {
+	URL string `json:"url"`
+}
+
+type DiscordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+type DiscordMessageRequest struct {
+	Content         string                 `json:"content"`
+	AllowedMentions DiscordAllowedMentions `json:"allowed_mentions"`
+	Flags           int                    `json:"flags,omitempty"`
+}
+
+// 재시도 끝에 보내지 못해 /retry-failed 를 기다리는 글이다.
+type FailedSend struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ChannelID      string             `bson:"channelId" json:"channelId"`
+	BlogName       string             `bson:"blogName" json:"blogName"`
+	RssURL         string             `bson:"rssUrl" json:"rssUrl"`
+	ItemTitle      string             `bson:"itemTitle" json:"itemTitle"`
+	ItemLink       string             `bson:"itemLink" json:"itemLink"`
+	Content        string             `bson:"content" json:"content"`
+	SuppressEmbeds bool               `bson:"suppressEmbeds,omitempty" json:"suppressEmbeds,omitempty"`
+	Error          string             `bson:"error" json:"error"`
+	FailedAt       time.Time          `bson:"failedAt" json:"failedAt"`
+}
+
+type BotConfig struct {
+	ID                string    `bson:"_id" json:"_id"`
+	Paused            bool      `bson:"paused" json:"paused"`
+	LastErrorReportAt time.Time `bson:"lastErrorReportAt,omitempty" json:"lastErrorReportAt,omitempty"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type FeedMetric struct {
+	RssURL    string `bson:"_id"`
+	BlogName  string `bson:"blogName"`
+	PostsSent int    `bson:"postsSent"`
+	Channels  int    `bson:"channels"`
+}
+
+type BotMetrics struct {
+	TotalChannels  int
+	TotalFeeds     int
+	TotalPostsSent int
+	FailingFeeds   int
+	FeedFailures   int
+	TopFeeds       []FeedMetric
 }
 
 const (
 	InteractionTypePing                = 1
 	InteractionTypeApplicationCommand  = 2
+	InteractionTypeMessageComponent    = 3
+	InteractionTypeAutocomplete        = 4
 	ResponseTypePong                   = 1
 	ResponseTypeChannelMessage         = 4
 	ResponseTypeDeferredChannelMessage = 5
+	ResponseTypeUpdateMessage          = 7
+	ResponseTypeAutocompleteResult     = 8
+	ComponentTypeActionRow             = 1
+	ComponentTypeButton                = 2
+	ButtonStylePrimary                 = 1
+	ButtonStyleSecondary               = 2
+	MaxCustomIDLength                  = 100
+	AddConfirmCustomIDPrefix           = "add-confirm:"
+	AddConfirmLatestCustomIDPrefix     = "add-confirm-latest:"
+	AddCancelCustomID                  = "add-cancel"
+	CommandTypeMessage                 = 3
+	ContextCommandAddFeed              = "Add as RSS feed"
 	MessageFlagEphemeral               = 64
+	MessageFlagSuppressEmbeds          = 1 << 2
+	MaxRetryFailedSends                = 5
+	MaxRepairFeeds                     = 5
+	PermissionAdministrator            = 1 << 3
+	PermissionManageChannels           = 1 << 4
+	PermissionManageGuild              = 1 << 5
+	PermissionViewChannel              = 1 << 10
+	PermissionSendMessages             = 1 << 11
+	DiscordAPIBaseURL                  = "https://discord.com/api/v10"
+	DiscordMessageLimit                = 2000
+	MaxNoteLength                      = 200
+	MaxBlockKeywords                   = 20
+	MaxRequireCategories               = 20
+	MaxFilterKeywords                  = 20
+	MaxKeywordLength                   = 50
+	MaxFeedBodySize                    = 10 << 20
+	MinPollInterval                    = 15
+	MaxPollInterval                    = 24 * 60
+	SlowFeedThreshold                  = 10 * time.Second
+	DefaultExpensiveCommandCooldown    = 10 * time.Second
+	DefaultMaxFeedsPerChannel          = 50
+	DisplayTimeLayout                  = "2006-01-02 15:04"
+	MongoRetryAttempts                 = 3
+	MongoRetryBaseDelay                = 200 * time.Millisecond
+	DefaultUserAgent                   = "Mozilla/5.0 (compatible; FeedNyang/1.0; +https://github.com/nmin11/feednyang)"
+	MaxUserAgentLength                 = 300
+	MaxFuzzyFeedDistance               = 2
+	MaxMinPostInterval                 = 3600
+	MaxMinItemAge                      = 1440
+	BurstPreviewCount                  = 5
+	MaxBurstThreshold                  = 100
+	MaxMirrorChannels                  = 5
+	MaxEmbedsPerMessage                = 10
+	MaxAutocompleteChoices             = 25
+	MaxAutocompleteChoiceLength        = 100
+	MaxDisplayURLLength                = 60
+	CompactDisplayURLLength            = 30
+	FeedsPerListPage                   = 10
+	FeedErrorLoginRequired             = "login-required"
+	FeedStatusActive                   = "active"
+	FeedStatusFailing                  = "failing"
+	FeedStatusLoginRequired            = "login-required"
+	LanguageFilterKorean               = "ko"
+	LanguageFilterNonKorean            = "en"
+	LanguageFilterOff                  = "off"
+	DisplayFieldTitle                  = "title"
+	DisplayFieldDate                   = "date"
+	DisplayFieldAuthor                 = "author"
+	DisplayFieldDescription            = "description"
+	DisplayFieldLink                   = "link"
+	DeliveryModeItem                   = "item"
+	DeliveryModeSummary                = "summary"
 
 	AlreadyRegisteredFeed             = "⚠️ 이미 등록된 피드다냥"
+	TooManyFeedsInChannel             = "❌ 이 채널에는 피드를 최대 %d개까지 등록할 수 있다냥! 안 보는 피드를 `/remove` 로 지우고 다시 추가하라냥"
 	FeedNotFound                      = "❌ 피드 못 찾겠다냥..."
 	FeedSuccessfullyAdded             = "✅ 피드가 성공적으로 추가되었다냥~!"
+	AddPreviewQuestion                = "🔍 이 피드를 추가할까냥?"
+	AddCancelled                      = "🙅 피드 추가를 취소했다냥~"
+	NoURLInMessage                    = "❌ 메시지에서 링크를 못 찾겠다냥... 피드 주소가 있는 메시지에서 다시 해보라냥!"
 	FeedSuccessfullyDeleted           = "✅ 피드가 성공적으로 삭제되었다냥~!"
+	FeedNoteSuccessfullyUpdated       = "✅ 피드 메모가 저장되었다냥~!"
+	FeedNoteSuccessfullyCleared       = "✅ 피드 메모가 삭제되었다냥~!"
+	BlockKeywordAdded                 = "✅ 차단 키워드가 추가되었다냥~!"
+	BlockKeywordRemoved               = "✅ 차단 키워드가 해제되었다냥~!"
+	RequireCategoryAdded              = "✅ 이제부터 이 카테고리가 달린 글도 보내준다냥~!"
+	RequireCategoryRemoved            = "✅ 카테고리 조건을 해제했다냥~!"
+	FilterKeywordAdded                = "✅ 이제부터 이 키워드가 들어간 글도 보내준다냥~!"
+	FilterKeywordAlreadyAdded         = "✅ 이미 등록한 필터 키워드다냥~!"
+	MirrorChannelAdded                = "✅ 미러 채널이 추가되었다냥~!"
+	MirrorChannelRemoved              = "✅ 미러 채널이 해제되었다냥~!"
+	FeedChannelSet                    = "✅ 이제부터 이 피드의 새 글은 다른 채널로 보낸다냥~!"
+	FeedChannelCleared                = "✅ 이제부터 이 피드의 새 글을 다시 이 채널로 보낸다냥~!"
+	FeedSuccessfullyMoved             = "✅ 피드 순서가 변경되었다냥~!"
+	FeedAlreadyAtTop                  = "⚠️ 이미 맨 위에 있는 피드다냥~"
+	FeedAlreadyAtBottom               = "⚠️ 이미 맨 아래에 있는 피드다냥~"
+	FeedSuccessfullyPaused            = "✅ 피드를 일시정지했다냥~! 읽던 위치와 통계는 그대로 남겨둔다냥"
+	FeedSuccessfullyResumed           = "✅ 피드를 다시 보내기 시작한다냥~!"
+	FeedAlreadyPaused                 = "⚠️ 이미 일시정지된 피드다냥~"
+	FeedNotPaused                     = "⚠️ 일시정지된 피드가 아니다냥~"
+	DeliveryModeChangedToItem         = "✅ 이제부터 새 글을 하나씩 보내준다냥~!"
+	DeliveryModeChangedToSummary      = "✅ 이제부터 새 글이 여러 개면 피드별로 묶어서 한 번에 보내준다냥~!"
+	ThreadModeEnabled                 = "✅ 이제부터 피드마다 스레드를 만들어서 그 안에 새 글을 보내준다냥~!"
+	ThreadModeDisabled                = "✅ 이제부터 새 글을 채널에 바로 보내준다냥~!"
+	SuppressEmbedsEnabled             = "✅ 이제부터 링크 미리보기 카드 없이 새 글을 보내준다냥~!"
+	SuppressEmbedsDisabled            = "✅ 이제부터 링크 미리보기 카드와 함께 새 글을 보내준다냥~!"
+	UpdateNoticesEnabled              = "✅ 이제부터 마지막으로 보낸 글이 수정되면 알려준다냥~!"
+	UpdateNoticesDisabled             = "✅ 이제부터 글이 수정되어도 알리지 않는다냥~!"
+	DefaultFeedsOptedOut              = "✅ 이제부터 기본 피드를 이 채널에 다시 채워 넣지 않는다냥~! (지금 있는 피드는 그대로다냥)"
+	DefaultFeedsFollowed              = "✅ 이제부터 기본 피드 목록에 빠진 피드가 있으면 이 채널에 채워 넣는다냥~!"
+	DisplayFieldsUpdated              = "✅ 글에 표시할 항목이 변경되었다냥~!"
+	DisplayFieldsReset                = "✅ 글에 표시할 항목을 기본값(제목, 링크)으로 되돌렸다냥~!"
+	MinPostIntervalUpdated            = "✅ 이 채널에는 최소 %d초 간격으로 글을 보낸다냥~!"
+	MinPostIntervalDisabled           = "✅ 이 채널의 최소 전송 간격을 해제했다냥~!"
+	MinItemAgeUpdated                 = "✅ 올라온 지 %d분이 지난 글만 보낸다냥~!"
+	MinItemAgeDisabled                = "✅ 새 글을 기다리지 않고 바로 보낸다냥~!"
+	BurstThresholdUpdated             = "✅ 한 피드에 새 글이 %d개보다 많이 밀려 있으면 최신 %d개만 묶어서 보여준다냥~!"
+	BurstThresholdDisabled            = "✅ 밀린 글도 모두 보내준다냥~!"
+	LanguageFilterSetToKorean         = "✅ 이제부터 한국어 글만 보내준다냥~!"
+	LanguageFilterSetToNonKorean      = "✅ 이제부터 한국어가 아닌 글만 보내준다냥~!"
+	LanguageFilterDisabled            = "✅ 이제부터 언어와 상관없이 모든 글을 보내준다냥~!"
+	UserAgentSuccessfullyUpdated      = "✅ 피드 User-Agent 가 변경되었다냥~!"
+	UserAgentSuccessfullyReset        = "✅ 피드 User-Agent 를 기본값으로 되돌렸다냥~!"
+	IncludeContentEnabled             = "✅ 이제부터 새 글의 본문도 같이 보내준다냥~!"
+	IncludeContentDisabled            = "✅ 이제부터 새 글의 본문은 빼고 보내준다냥~!"
+	FeedHoursUpdated                  = "✅ 이 피드의 새 글은 %02d:00 ~ %02d:00 (KST) 사이에만 보내준다냥~!"
+	FeedHoursCleared                  = "✅ 이 피드의 새 글은 이제 시간과 상관없이 보내준다냥~!"
+	GlobalPauseEnabled                = "⛔ 모든 채널의 피드 전송을 멈췄다냥!"
+	GlobalPauseDisabled               = "✅ 모든 채널의 피드 전송을 다시 시작한다냥~!"
+	NoFailedSends                     = "✅ 다시 보낼 글이 없다냥~!"
+	NothingToRepair                   = "✅ 고칠 피드가 없다냥~!"
+	FeedsRepaired                     = "🔧 중복 확인 기준이 빠진 피드를 고쳤다냥!\n✅ 고침: %d개\n❌ 실패: %d개\n📦 남은 피드: %d개"
+	FailedSendsRetried                = "🔁 보내지 못한 글을 다시 보냈다냥!\n✅ 성공: %d개\n❌ 실패: %d개\n📦 남은 글: %d개"
 	ErrorOccurredOnAddFeed            = "❌ 피드 추가에 실패했다냥..."
+	ErrorOccurredOnSendLatest         = "⚠️ 최신 글을 보내지 못했다냥... 봇이 이 채널에 글을 쓸 수 있는지 확인하라냥!"
+	BotCannotPostInChannel            = "⚠️ 이 채널에 메시지를 보낼 권한이 없다냥! 피드는 등록했지만, 봇에게 `채널 보기` 와 `메시지 보내기` 권한을 주기 전까지는 새 글을 보낼 수 없다냥"
 	ErrorOccurredOnDatabaseConnection = "❌ 데이터베이스 연결 오류다냥..."
 	ErrorOccurredOnDeleteFeed         = "❌ 피드 삭제에 실패했다냥..."
 	ErrorOccurredOnFeedParsing        = "❌ 피드 조회 중 오류가 발생했다냥~"
+	ErrorOccurredOnUpdateFeed         = "❌ 피드 수정에 실패했다냥..."
+	SlowFeedNotice                    = "🐢 늘 느리게 불러와지는 피드다냥... 다른 피드 주소를 찾거나 삭제하는 것도 생각해보라냥!"
+	FeedRequiresLogin                 = "🔒 피드가 로그인을 요구한다냥... 공개된 피드 URL 인지 확인하라냥!"
 	InvalidRSSFeed                    = "❌ RSS 피드가 유효하지 않다냥!"
+	InvalidFeedStatus                 = "❌ 상태는 active, failing, login-required 중 하나여야 한다냥!"
+	NoFeedWithStatus                  = "✅ 이 채널에 %s 상태인 피드가 없다냥~"
+	InvalidListPage                   = "❌ 없는 페이지다냥! 1 ~ %d 페이지 중에서 고르라냥"
+	InvalidFeedPosition               = "❌ 피드 번호가 범위를 벗어났다냥! (1 ~ %d)"
+	AmbiguousFeed                     = "🤔 비슷한 피드가 여러 개다냥! 번호로 다시 입력하라냥~"
+	AmbiguousFeedName                 = "🤔 이름이 같은 피드가 여러 개다냥! 번호나 URL 로 다시 입력하라냥~"
 	NoRegisteredFeed                  = "⚠️ 이 채널에 등록된 피드가 없다냥~"
+	CommandOnCooldown                 = "⏳ 잠시 후에 다시 시도하라냥 (%d초 남음)"
+	ReadonlyModeNotice                = "🛠️ 지금은 점검 중이라 피드를 바꿀 수 없다냥... 조회 명령어는 쓸 수 있다냥~"
+	PingPostSent                      = "✅ 테스트 메시지를 보냈다냥~!"
+	ErrorOccurredOnPingPost           = "❌ 메시지 전송에 실패했다냥... 봇이 이 채널에 글을 쓸 수 있는지 확인하라냥!"
+	ManagerOnlyCommand                = "❌ 채널 관리 권한이 있어야 쓸 수 있는 명령어다냥!"
+	PingPostTooLong                   = "❌ 메시지가 너무 길다냥! (최대 2000자)"
+	ShouldInputPingPost               = "❌ 보낼 메시지를 입력하라냥!"
+	OwnerOnlyCommand                  = "❌ 봇 관리자만 쓸 수 있는 명령어다냥!"
+	NoteTooLong                       = "❌ 메모가 너무 길다냥! (최대 200자)"
+	UserAgentTooLong                  = "❌ User-Agent 가 너무 길다냥! (최대 300자)"
+	KeywordTooLong                    = "❌ 키워드가 너무 길다냥! (최대 50자)"
+	CategoryTooLong                   = "❌ 카테고리가 너무 길다냥! (최대 50자)"
+	TooManyBlockKeywords              = "❌ 차단 키워드는 피드당 최대 20개까지다냥!"
+	TooManyRequireCategories          = "❌ 카테고리 조건은 피드당 최대 20개까지다냥!"
+	TooManyFilterKeywords             = "❌ 필터 키워드는 피드당 최대 20개까지다냥!"
 	ShouldInputRssUrl                 = "❌ RSS URL을 입력하라냥!"
 	ShouldInputFeed                   = "❌ 삭제할 피드를 입력하라냥! (번호 / 블로그 제목 / URL)"
+	ShouldInputNoteFeed               = "❌ 메모를 남길 피드를 입력하라냥! (번호 / 블로그 제목 / URL)"
+	ShouldInputStatsFeed              = "❌ 통계를 볼 피드를 입력하라냥! (번호 / 블로그 제목 / URL)"
+	ShouldInputBlockKeyword           = "❌ 피드와 차단할 키워드를 입력하라냥!"
+	ShouldInputRequireCategory        = "❌ 피드와 받을 카테고리를 입력하라냥!"
+	ShouldInputFilterKeyword          = "❌ 피드와 받을 키워드를 입력하라냥!"
+	ShouldInputMirror                 = "❌ 피드와 같이 글을 보낼 채널을 입력하라냥!"
+	ShouldInputFeedChannel            = "❌ 보낼 채널을 바꿀 피드를 입력하라냥!"
+	MirrorChannelIsPrimary            = "❌ 이 채널은 이미 피드가 등록된 채널이다냥!"
+	TooManyMirrorChannels             = "❌ 미러 채널은 피드당 최대 5개까지다냥!"
+	MirrorChannelNotAccessible        = "❌ 봇이 그 채널에 접근할 수 없다냥... 봇 권한을 확인하라냥!"
+	ShouldInputReorder                = "❌ 옮길 피드 번호와 새 위치를 입력하라냥!"
+	ShouldInputMoveFeed               = "❌ 옮길 피드를 입력하라냥! (번호 / 블로그 제목 / URL)"
+	ShouldInputDeliveryMode           = "❌ item 또는 summary 를 입력하라냥!"
+	ShouldInputUserAgentFeed          = "❌ User-Agent 를 바꿀 피드를 입력하라냥! (번호 / 블로그 제목 / URL)"
+	ShouldInputContentFeed            = "❌ 본문을 보낼지 정할 피드와 on / off 를 입력하라냥!"
+	ShouldInputFeedHours              = "❌ 피드와 시작 / 끝 시각을 입력하라냥! (0 ~ 23시)"
+	ShouldInputDisplayFields          = "❌ 표시할 항목을 입력하라냥! (title / date / author / description / link, 쉼표로 구분)"
+	InvalidDisplayField               = "❌ 알 수 없는 항목이다냥! (title / date / author / description / link 중에서 고르라냥)"
+	ShouldInputPostInterval           = "❌ 전송 간격을 초 단위로 입력하라냥! (0 ~ 3600, 0 이면 해제)"
+	ShouldInputMinItemAge             = "❌ 피드와 기다릴 시간을 분 단위로 입력하라냥! (0 ~ 1440, 0 이면 해제)"
+	ShouldInputBurstThreshold         = "❌ 밀린 글 기준 개수를 입력하라냥! (5 ~ 100, 0 이면 해제)"
+	ShouldInputLanguageFilter         = "❌ ko, en, off 중에서 입력하라냥!"
+	ShouldInputOnOff                  = "❌ on 또는 off 를 입력하라냥!"
+	RankCommandDisabled               = "🔒 순위 보기는 봇 관리자가 꺼 두었다냥!"
+	NoRankForChannel                  = "📭 이 채널은 아직 보낸 글이 없어서 순위가 없다냥! 피드를 추가하고 기다려보라냥~"
 	UnknownCommand                    = "❌ 뭔 말이냥..."
+	UnknownHelpTopic                  = "❌ 그런 명령어는 없다냥! `/help` 로 전체 명령어를 확인하라냥~"
 	HelpMessage                       = "📚 **피드냥 명령어 도움말** 📚\n\n" +
-		"🔸 `/add <RSS_URL>` - RSS 피드를 추가하라냥!\n" +
-		"🔸 `/list` - 등록된 피드 목록을 확인하라냥!\n" +
-		"🔸 `/remove <번호|이름|URL>` - 피드를 삭제하라냥!\n" +
+		"🔸 `/add <RSS_URL> [latest]` - RSS 피드를 추가하라냥!\n" +
+		"🔸 `/list [rich] [status] [page]` - 등록된 피드 목록을 확인하라냥! (rich 를 켜면 사이트 로고와 함께, status 로 상태별로, 한 페이지에 10개씩 보여준다냥)\n" +
+		"🔸 `/remove <번호|ID|이름|URL>` - 피드를 삭제하라냥!\n" +
+		"🔸 `/note <번호|ID|이름|URL> [메모]` - 피드에 메모를 남기라냥! (메모 생략 시 삭제)\n" +
+		"🔸 `/pause <번호|ID|이름|URL>` - 피드를 잠시 멈추라냥! (`/resume` 으로 다시 시작)\n" +
+		"🔸 `/block <번호|ID|이름|URL> <키워드>` - 키워드가 들어간 글을 차단하라냥! (다시 입력하면 해제)\n" +
+		"🔸 `/filter <번호|ID|이름|URL> <키워드>` - 키워드가 들어간 글만 받으라냥!\n" +
+		"🔸 `/require-category <번호|ID|이름|URL> <카테고리>` - 카테고리가 달린 글만 받으라냥! (다시 입력하면 해제)\n" +
+		"🔸 `/mirror <번호|ID|이름|URL> <채널>` - 피드의 새 글을 다른 채널에도 같이 보내라냥! (다시 입력하면 해제)\n" +
+		"🔸 `/feed-channel <번호|ID|이름|URL> [채널]` - 피드의 새 글을 이 채널 대신 다른 채널로 보내라냥! (비우면 해제)\n" +
+		"🔸 `/reorder <번호> <새 위치>` - 피드 순서를 바꾸라냥!\n" +
+		"🔸 `/up <번호|ID|이름|URL>`, `/down <번호|ID|이름|URL>` - 피드를 한 칸 위 / 아래로 옮기라냥!\n" +
+		"🔸 `/delivery-mode <item|summary>` - 새 글을 하나씩 보낼지, 피드별로 묶어 보낼지 정하라냥!\n" +
+		"🔸 `/thread-mode <on|off>` - 피드별 스레드에 새 글을 모아 보낼지 정하라냥!\n" +
+		"🔸 `/suppress-embeds <on|off>` - 새 글의 링크 미리보기 카드를 숨길지 정하라냥!\n" +
+		"🔸 `/update-notices <on|off>` - 보낸 글이 수정되면 알려줄지 정하라냥!\n" +
+		"🔸 `/opt-out <on|off>` - 기본 피드를 이 채널에 다시 채워 넣지 않도록 하라냥!\n" +
+		"🔸 `/post-interval <초>` - 이 채널에 글을 보내는 최소 간격을 정하라냥! (0 이면 해제)\n" +
+		"🔸 `/burst-threshold <개수>` - 밀린 글이 이보다 많으면 최신 5개만 묶어서 보내라냥! (0 이면 해제)\n" +
+		"🔸 `/language <ko|en|off>` - 한국어 글만, 또는 한국어가 아닌 글만 받으라냥!\n" +
+		"🔸 `/fields <항목,...|default>` - 글에 표시할 항목을 고르라냥! (title / date / author / description / link)\n" +
+		"🔸 `/user-agent <번호|ID|이름|URL> [User-Agent]` - 피드를 가져올 때 쓸 User-Agent 를 바꾸라냥! (생략 시 기본값)\n" +
+		"🔸 `/content <번호|ID|이름|URL> <on|off>` - 새 글의 본문도 같이 보낼지 정하라냥!\n" +
+		"🔸 `/min-age <번호|ID|이름|URL> <분>` - 올라온 지 이만큼 지난 글만 보내라냥! (0 이면 해제)\n" +
+		"🔸 `/feed-hours <번호|ID|이름|URL> <시작> <끝>` - 피드의 새 글을 보낼 시간대를 정하라냥!\n" +
+		"🔸 `/stats-feed <번호|ID|이름|URL>` - 피드 하나의 상세 통계를 보여준다냥!\n" +
+		"🔸 `/feed-info <RSS_URL>` - 피드의 원본 메타데이터를 보여준다냥!\n" +
+		"🔸 `/stats` - 이 채널 피드들의 활동 요약을 보여준다냥!\n" +
+		"🔸 `/rank` - 이 채널이 전체 채널 중에서 글을 몇 번째로 많이 받았는지 보여준다냥!\n" +
+		"🔸 `/ping-post <메시지>` - 이 채널에 테스트 메시지를 보내서 봇이 글을 쓸 수 있는지 확인하라냥! (채널 관리자 전용)\n" +
 		"🔸 `/help` - 이 도움말을 보여준다냥!\n\n" +
 		"💡 **사용 예시:**\n" +
 		"• `/add https://example.com/rss`\n" +
-		"• `/remove 1` 또는 `/remove 블로그이름`\n\n" +
+		"• `/remove 1`, `/remove #1a2b3c4d` 또는 `/remove 블로그이름`\n" +
+		"• `/note 1 ML 팀 참고용`\n" +
+		"• `/block 1 광고`\n" +
+		"• `/help add` - 명령어 하나의 자세한 설명을 보여준다냥!\n\n" +
 		"🚀 **피드냥**은 기술 블로그 RSS 피드를 관리해주는 봇이다냥~!"
 )
 
-func verifyDiscordSignature(signature, timestamp, body, publicKey string) bool {
+var errFeedLoginRequired = errors.New("feed requires login")
+
+var (
+	mongoURIMu     sync.Mutex
+	cachedMongoURI string
+	// 웜 컨테이너의 다음 호출이 연결 풀을 그대로 쓰도록 닫지 않는다
+	mongoClientMu     sync.Mutex
+	cachedMongoClient *mongo.Client
+	// 캐시된 클라이언트로 한 작업이 실패했으면 다음 연결 때 한 번 Ping 해 본다
+	mongoClientSuspect bool
+	// 테스트에서는 서버 없이 통과하도록 바꾼다
+	pingMongoDB = func(ctx context.Context, client *mongo.Client) error {
+		return client.Ping(ctx, nil)
+	}
+	// 테스트에서는 네트워크 없이 바꾼다
+	validateFeed = validateRSSFeed
+	// 시작할 때 한 번만 디코딩해서 모든 요청의 서명 검증에 재사용한다
+	discordPublicKey ed25519.PublicKey
+	botTokenMu       sync.Mutex
+	cachedBotToken   string
+
+	kst       = time.FixedZone("KST", 9*60*60)
+	utf8BOM   = []byte{0xEF, 0xBB, 0xBF}
+	gzipMagic = []byte{0x1f, 0x8b}
+	// 피드 본문 맨 앞의 XML 선언에서 encoding 값을 찾는다
+	xmlEncodingPattern = regexp.MustCompile(`<\?xml[^>]*?encoding\s*=\s*["']([^"']+)["']`)
+	// 메시지 본문에서 첫 번째 링크를 찾는다. Discord 의 <URL> 표기도 꺾쇠를 빼고 찾는다
+	messageURLPattern = regexp.MustCompile(`https?://[^\s<>]+`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	// CDATA 안에 한 번 더 이스케이프된 HTML 태그나 엔티티 (예: &lt;p&gt;, &amp;nbsp;)
+	escapedHTMLPattern = regexp.MustCompile(`&lt;/?[a-zA-Z][^<>]*?&gt;|&amp;(?:[a-zA-Z]+|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+	// 제목은 항상 표시하고, 나머지는 여기 적힌 순서대로 표시한다
+	displayFieldOrder = []string{DisplayFieldTitle, DisplayFieldDate, DisplayFieldAuthor, DisplayFieldDescription, DisplayFieldLink}
+	displayFieldNames = map[string]string{
+		DisplayFieldTitle:       "제목",
+		DisplayFieldDate:        "날짜",
+		DisplayFieldAuthor:      "작성자",
+		DisplayFieldDescription: "요약",
+		DisplayFieldLink:        "링크",
+	}
+
+	// 명령어별 자세한 도움말. 새 명령어를 추가하면 여기에도 추가한다
+	commandHelp = map[string]string{
+		"add": "🔸 `/add <RSS_URL> [latest]`\n" +
+			"RSS / Atom 피드를 이 채널에 추가한다냥!\n\n" +
+			"• 추가하기 전에 피드를 직접 불러와서 올바른 피드인지 확인한다냥\n" +
+			"• 피드 제목과 최신 글을 미리 보여주고, `추가` 버튼을 눌러야 추가된다냥\n" +
+			"• 피드에 배너 이미지가 있으면 첫 글을 보낼 때 배너와 함께 구독을 알려준다냥\n" +
+			"• `latest` 를 켜면 추가하자마자 가장 최신 글 하나를 바로 보내준다냥 (끄면 새 글이 올라올 때까지 조용히 기다린다냥)\n" +
+			"• 링크가 있는 메시지를 우클릭하고 `앱 → Add as RSS feed` 를 눌러도 추가할 수 있다냥\n" +
+			"• 봇에게 이 채널에 메시지를 보낼 권한이 없으면 피드는 등록하되 경고를 보여준다냥\n" +
+			"• 이미 등록된 피드면 추가하지 않는다냥 (http / https, 끝의 `/` 차이는 같은 피드로 본다냥)\n" +
+			"• 채널 하나에는 피드를 최대 50개까지 (봇 관리자가 바꿀 수 있다냥) 등록할 수 있다냥\n" +
+			"• 로그인이 필요한 피드나 http(s) 가 아닌 주소는 추가할 수 없다냥\n\n" +
+			"💡 `/add https://d2.naver.com/d2.atom`",
+		"list": "🔸 `/list [rich] [status] [page]`\n" +
+			"이 채널에 등록된 피드를 번호, URL, 전송한 글 수와 함께 보여준다냥!\n\n" +
+			"• 메모, 차단 키워드, 로그인 필요 여부도 같이 보여준다냥\n" +
+			"• 여기 나오는 번호를 다른 명령어에서 그대로 쓸 수 있다냥\n" +
+			"• 너무 긴 URL 은 줄여서 보여준다냥 (다른 명령어에는 번호나 `#` ID 를 쓰면 된다냥)\n" +
+			"• 한 페이지에 10개씩 보여주니, 피드가 많으면 `page` 로 다음 페이지를 보라냥\n" +
+			"• `rich` 를 켜면 피드마다 사이트 로고가 달린 카드로 보여준다냥\n" +
+			"• `status` 로 `active` (정상), `failing` (최근 조회 실패), `login-required` (로그인 필요) 피드만 골라 볼 수 있다냥",
+		"remove": "🔸 `/remove <번호|ID|이름|URL>`\n" +
+			"피드를 삭제한다냥!\n\n" +
+			"• 입력하는 동안 이 채널의 피드를 후보로 보여주니 골라서 지우면 된다냥\n" +
+			"• 이름은 띄어쓰기 / 대소문자를 무시하고, 일부만 입력해도 찾아준다냥\n" +
+			"• 비슷한 피드나 이름이 같은 피드가 여러 개면 후보 목록을 보여주니 번호나 URL 로 다시 입력하라냥\n" +
+			"• `/list` 에 보이는 `#` ID 는 순서가 바뀌어도 그대로라서 번호 대신 쓰기 좋다냥\n\n" +
+			"💡 `/remove 1`, `/remove netflix`",
+		"pause": "🔸 `/pause <번호|ID|이름|URL>`\n" +
+			"피드의 새 글을 잠시 보내지 않는다냥!\n\n" +
+			"• 읽던 위치와 전송 통계는 그대로 남아서 피드를 지웠다 다시 추가하지 않아도 된다냥\n" +
+			"• 멈춘 동안에는 피드를 가져오지도 않는다냥\n" +
+			"• `/resume` 으로 다시 시작하면 멈춘 동안 올라온 글부터 보낸다냥\n\n" +
+			"💡 `/pause 1`",
+		"resume": "🔸 `/resume <번호|ID|이름|URL>`\n" +
+			"`/pause` 로 멈춘 피드를 다시 보내기 시작한다냥!\n\n" +
+			"• 멈춘 동안 올라온 글은 평소처럼 보내고, 한꺼번에 많이 올라왔으면 묶어서 보낸다냥\n\n" +
+			"💡 `/resume 1`",
+		"note": "🔸 `/note <번호|ID|이름|URL> [메모]`\n" +
+			"피드에 메모를 남긴다냥! (최대 200자)\n\n" +
+			"• 메모를 생략하면 기존 메모를 지운다냥\n\n" +
+			"💡 `/note 1 ML 팀 참고용`",
+		"block": "🔸 `/block <번호|ID|이름|URL> <키워드>`\n" +
+			"제목이나 요약에 키워드가 들어간 글은 보내지 않는다냥!\n\n" +
+			"• 대소문자를 구분하지 않는다냥\n" +
+			"• 이미 있는 키워드를 다시 입력하면 차단을 해제한다냥\n" +
+			"• 피드당 최대 20개, 키워드당 최대 50자다냥\n\n" +
+			"💡 `/block 1 광고`",
+		"filter": "🔸 `/filter <번호|ID|이름|URL> <키워드>`\n" +
+			"피드의 글 중에서 제목이나 요약에 이 키워드가 들어간 글만 보낸다냥!\n\n" +
+			"• 여러 개를 추가하면 그중 하나라도 들어간 글을 보낸다냥\n" +
+			"• 대소문자를 구분하지 않는다냥\n" +
+			"• 이미 있는 키워드를 다시 입력해도 한 번만 등록된다냥\n" +
+			"• 차단 키워드 (`/block`) 에 걸린 글은 필터 키워드가 있어도 보내지 않는다냥\n" +
+			"• 피드당 최대 20개, 키워드당 최대 50자다냥\n\n" +
+			"💡 `/filter 1 kafka`",
+		"require-category": "🔸 `/require-category <번호|ID|이름|URL> <카테고리>`\n" +
+			"피드의 글 중에서 이 카테고리 (태그) 가 달린 글만 보낸다냥!\n\n" +
+			"• 여러 개를 추가하면 그중 하나라도 달린 글을 보낸다냥\n" +
+			"• 대소문자를 구분하지 않고, 카테고리 이름이 정확히 같아야 한다냥\n" +
+			"• 카테고리가 하나도 없는 글은 보내지 않는다냥\n" +
+			"• 이미 있는 카테고리를 다시 입력하면 해제하고, 모두 해제하면 모든 글을 보낸다냥\n" +
+			"• 피드당 최대 20개, 카테고리당 최대 50자다냥\n\n" +
+			"💡 `/require-category 1 Backend`",
+		"mirror": "🔸 `/mirror <번호|ID|이름|URL> <채널>`\n" +
+			"피드의 새 글을 이 채널과 함께 다른 채널에도 보낸다냥!\n\n" +
+			"• 피드는 한 번만 가져오고, 전송 기록은 이 채널 기준으로만 남긴다냥\n" +
+			"• 이미 미러로 등록된 채널을 다시 입력하면 해제한다냥\n" +
+			"• 피드당 최대 5개까지고, 봇이 볼 수 있는 채널이어야 한다냥\n\n" +
+			"💡 `/mirror 1 #eng-announcements`",
+		"feed-channel": "🔸 `/feed-channel <번호|ID|이름|URL> [채널]`\n" +
+			"피드의 새 글을 이 채널 대신 다른 채널로 보낸다냥!\n\n" +
+			"• 피드 설정과 전송 기록은 계속 이 채널에서 관리한다냥\n" +
+			"• 채널을 비우거나 이 채널을 입력하면 다시 이 채널로 보낸다냥\n" +
+			"• 다른 채널로 보내는 피드는 스레드 모드를 쓰지 않는다냥\n" +
+			"• 봇이 볼 수 있는 채널이어야 한다냥\n\n" +
+			"💡 `/feed-channel 1 #frontend`",
+		"reorder": "🔸 `/reorder <번호> <새 위치>`\n" +
+			"피드 순서를 바꾼다냥! 전송 기록은 그대로 유지된다냥\n\n" +
+			"💡 `/reorder 5 1` - 5번 피드를 맨 위로 옮긴다냥",
+		"up": "🔸 `/up <번호|ID|이름|URL>`\n" +
+			"피드를 한 칸 위로 옮긴다냥! 이미 맨 위면 그대로 둔다냥\n\n" +
+			"💡 `/up netflix`",
+		"down": "🔸 `/down <번호|ID|이름|URL>`\n" +
+			"피드를 한 칸 아래로 옮긴다냥! 이미 맨 아래면 그대로 둔다냥\n\n" +
+			"💡 `/down 1`",
+		"delivery-mode": "🔸 `/delivery-mode <item|summary>`\n" +
+			"새 글을 보내는 방식을 정한다냥!\n\n" +
+			"• `item` - 새 글마다 메시지를 하나씩 보낸다냥 (기본값)\n" +
+			"• `summary` - 한 피드에 새 글이 여러 개면 피드 이름 아래 목록 하나로 묶어 보낸다냥\n" +
+			"• 목록이 2000자를 넘으면 빠지는 글 없이 메시지 여러 개로 나눠 보낸다냥",
+		"thread-mode": "🔸 `/thread-mode <on|off>`\n" +
+			"피드마다 스레드를 만들어서 새 글을 그 안에 모아 보낸다냥!\n\n" +
+			"• 피드의 첫 글로 스레드를 만들고, 다음 글부터는 스레드에 보낸다냥\n" +
+			"• 스레드가 삭제되거나 잠기면 다음 글을 보낼 때 새로 만든다냥\n" +
+			"• 봇에게 스레드 생성 / 스레드 메시지 권한이 필요하다냥",
+		"suppress-embeds": "🔸 `/suppress-embeds <on|off>`\n" +
+			"새 글 메시지에 링크 미리보기 카드를 붙이지 않는다냥! (기본값은 off)\n\n" +
+			"• 글이 여러 개 올라와도 채널이 큰 카드로 가득 차지 않는다냥\n" +
+			"• 켜면 새 글을 제목과 요약이 담긴 카드 (임베드) 대신 텍스트로 보낸다냥\n" +
+			"• 미러 채널과 피드 스레드로 보내는 글에도 똑같이 적용된다냥",
+		"update-notices": "🔸 `/update-notices <on|off>`\n" +
+			"피드마다 마지막으로 보낸 글이 수정되면 `✏️ 수정됨` 알림을 보낸다냥! (기본값은 off)\n\n" +
+			"• Atom 피드의 `updated` 시각이 바뀐 글을 새 글로 보내지 않고 수정 알림으로 보낸다냥\n" +
+			"• 피드마다 가장 최근에 보낸 글 하나만 확인한다냥\n" +
+			"• 피드 스레드를 쓰고 있으면 스레드로 보낸다냥",
+		"opt-out": "🔸 `/opt-out <on|off>`\n" +
+			"기본 피드 동기화에서 이 채널을 뺀다냥!\n\n" +
+			"• `on` 이면 일부러 지운 기본 피드를 다시 채워 넣지 않는다냥\n" +
+			"• `off` 면 기본 피드 목록에 빠진 피드가 있을 때 이 채널에도 채워 넣는다냥\n" +
+			"• 지금 등록된 피드는 건드리지 않는다냥",
+		"post-interval": "🔸 `/post-interval <초>`\n" +
+			"이 채널에 글을 보내는 최소 간격을 정한다냥! (0 ~ 3600초)\n\n" +
+			"• 간격 안에 보내지 못한 글은 다음 실행으로 미뤄서 빠뜨리지 않는다냥\n" +
+			"• 0 을 입력하면 제한을 해제한다냥\n\n" +
+			"💡 `/post-interval 300`",
+		"burst-threshold": "🔸 `/burst-threshold <개수>`\n" +
+			"한 피드에 새 글이 이 개수보다 많이 밀려 있으면 최신 5개만 묶어서 한 번에 보여준다냥! (5 ~ 100개)\n\n" +
+			"• 오래 멈춰 있던 블로그가 글을 한꺼번에 올렸을 때 채널이 도배되지 않는다냥\n" +
+			"• 나머지 밀린 글은 보내지 않고 건너뛴다냥\n" +
+			"• 0 을 입력하면 해제한다냥 (기본값)\n\n" +
+			"💡 `/burst-threshold 20`",
+		"language": "🔸 `/language <ko|en|off>`\n" +
+			"글의 언어로 이 채널에 보낼 글을 거른다냥!\n\n" +
+			"• `ko` - 제목에 한글이 있는 글만 보낸다냥\n" +
+			"• `en` - 제목에 한글이 없는 글만 보낸다냥\n" +
+			"• `off` - 언어와 상관없이 모두 보낸다냥 (기본값)\n" +
+			"• 걸러진 글은 나중에 다시 보내지 않는다냥",
+		"fields": "🔸 `/fields <항목,...|default>`\n" +
+			"글 메시지에 표시할 항목을 고른다냥!\n\n" +
+			"• 항목: `title`, `date`, `author`, `description`, `link` (제목은 항상 표시한다냥)\n" +
+			"• `default` 를 입력하면 기본값(제목, 링크)으로 돌아간다냥\n" +
+			"• `summary` 모드로 묶어 보낼 때는 제목 + 링크 목록으로 보낸다냥\n" +
+			"• 항목을 고르면 새 글을 카드 (임베드) 대신 고른 항목만 담은 텍스트로 보낸다냥\n\n" +
+			"💡 `/fields date, author, link`",
+		"user-agent": "🔸 `/user-agent <번호|ID|이름|URL> [User-Agent]`\n" +
+			"피드를 가져올 때 쓸 User-Agent 를 바꾼다냥! (최대 300자)\n\n" +
+			"• 봇을 막는 블로그에 브라우저 User-Agent 를 쓰고 싶을 때 쓴다냥\n" +
+			"• User-Agent 를 생략하면 기본값으로 돌아간다냥",
+		"content": "🔸 `/content <번호|ID|이름|URL> <on|off>`\n" +
+			"피드의 새 글을 보낼 때 링크만이 아니라 본문도 같이 보낸다냥! (기본값 off)\n\n" +
+			"• 짧은 글이 올라오는 마이크로블로그나 뉴스레터에 쓰기 좋다냥\n" +
+			"• HTML 태그는 지우고, 메시지 한 개 (2000자) 에 들어가는 만큼만 보낸다냥\n" +
+			"• 본문이 없는 피드는 요약을 대신 보낸다냥\n" +
+			"• 글을 하나씩 보낼 때만 적용되고, 묶어 보낼 때는 제목 + 링크 목록으로 보낸다냥",
+		"min-age": "🔸 `/min-age <번호|ID|이름|URL> <분>`\n" +
+			"피드의 새 글을 올라온 지 정한 시간이 지난 뒤에 보낸다냥! (0 ~ 1440분)\n\n" +
+			"• 예약 글이나 임시 글이 잠깐 피드에 올라왔다가 사라지는 블로그에 쓰기 좋다냥\n" +
+			"• 아직 기다리는 글은 버리지 않고 시간이 지난 뒤 실행에서 보낸다냥\n" +
+			"• 발행 시각이 없는 글은 기다리지 않고 보낸다냥\n" +
+			"• 0 을 입력하면 해제한다냥\n\n" +
+			"💡 `/min-age 1 10`",
+		"feed-hours": "🔸 `/feed-hours <번호|ID|이름|URL> <시작> <끝>`\n" +
+			"피드의 새 글을 정해진 시간대(KST)에만 보낸다냥!\n\n" +
+			"• 시간대 밖에 올라온 글은 버리지 않고 시간대가 열리면 보낸다냥\n" +
+			"• `22 6` 처럼 자정을 넘기는 시간대도 된다냥 (끝 시각은 포함하지 않는다냥)\n" +
+			"• 시작과 끝을 같게 입력하면 시간대를 해제한다냥\n\n" +
+			"💡 `/feed-hours 1 9 18` - 09:00 ~ 18:00 에만 보낸다냥",
+		"stats-feed": "🔸 `/stats-feed <번호|ID|이름|URL>`\n" +
+			"피드 하나의 상세 통계를 보여준다냥!\n\n" +
+			"• 추가된 날짜, 전송한 글 수, 마지막 전송 시각, 연속 실패 횟수를 보여준다냥\n" +
+			"• 피드를 추가한 사람과 이 채널에 처음 피드를 등록한 사람도 보여준다냥 (알림은 가지 않는다냥)\n" +
+			"• 피드를 불러오는 데 걸린 평균 시간도 보여주고, 10초가 넘으면 느린 피드라고 알려준다냥\n" +
+			"• 피드를 직접 불러와서 최신 글도 보여준다냥\n" +
+			"• 피드를 불러오는 명령어라 채널마다 잠깐 기다렸다가 다시 쓸 수 있다냥 (기본 10초)",
+		"feed-info": "🔸 `/feed-info <RSS_URL>`\n" +
+			"등록하지 않은 피드라도 원본 메타데이터를 보여준다냥!\n\n" +
+			"• 피드 형식, 제목, 언어, 글 수, 최신 글 등을 확인할 수 있다냥\n" +
+			"• 피드를 불러오는 명령어라 채널마다 잠깐 기다렸다가 다시 쓸 수 있다냥 (기본 10초)\n\n" +
+			"💡 `/feed-info https://d2.naver.com/d2.atom`",
+		"stats": "🔸 `/stats`\n" +
+			"이 채널에 등록된 피드들이 얼마나 활발한지 한눈에 보여준다냥!\n\n" +
+			"• 피드 수, 보낸 글 수 합계, 글을 가장 많이 보낸 피드를 알려준다냥\n" +
+			"• 가장 먼저 추가한 피드와 가장 최근에 추가한 피드도 보여준다냥\n" +
+			"• `/pause` 로 멈춘 피드는 따로 모아서 보여준다냥\n" +
+			"• 피드 하나의 자세한 통계는 `/stats-feed` 로 보라냥",
+		"rank": "🔸 `/rank`\n" +
+			"이 채널이 받은 글 수 (모든 피드의 전송 수 합계) 가 전체 채널 중 몇 위인지, 상위 몇 % 인지 보여준다냥!\n\n" +
+			"• 다른 채널의 이름이나 전송 수는 보여주지 않고 순위만 알려준다냥\n" +
+			"• 전송 수가 같은 채널은 같은 순위로 본다냥\n" +
+			"• 봇 관리자가 꺼 두면 봇 관리자만 쓸 수 있다냥",
+		"ping-post": "🔸 `/ping-post <메시지>`\n" +
+			"이 채널에 테스트 메시지를 보내서 봇이 글을 쓸 수 있는지 확인한다냥!\n\n" +
+			"• 채널 관리 / 서버 관리 / 관리자 권한이 있어야 쓸 수 있다냥\n" +
+			"• 메시지 안의 멘션은 알림을 보내지 않는다냥",
+		"kill-switch": "🔸 `/kill-switch <on|off>`\n" +
+			"모든 채널의 피드 전송을 한 번에 멈추거나 다시 시작한다냥! (봇 관리자 전용)",
+		"metrics-dump": "🔸 `/metrics-dump`\n" +
+			"전체 채널 / 피드 / 전송 수와 많이 보낸 피드 순위를 보여준다냥! (봇 관리자 전용)",
+		"prometheus": "🔸 `/prometheus`\n" +
+			"전체 메트릭을 Prometheus 텍스트 형식으로 보여준다냥! (봇 관리자 전용)",
+		"retry-failed": "🔸 `/retry-failed`\n" +
+			"재시도 끝에 보내지 못한 글을 최근 것부터 5개씩 다시 보낸다냥! (봇 관리자 전용)\n\n" +
+			"• 다시 보내는 데 성공한 글은 목록에서 지운다냥\n" +
+			"• 보내지 못한 글은 7일 동안만 보관한다냥",
+		"repair": "🔸 `/repair`\n" +
+			"중복 확인 기준 (lastPostLink) 이 빠진 피드를 5개씩 다시 가져와서 채운다냥! (봇 관리자 전용)\n\n" +
+			"• 예전 기준 (lastPostTitle) 이 있으면 제목이 같은 글을, 없으면 가장 최신 글을 기준으로 잡는다냥",
+		"help": "🔸 `/help [명령어]`\n" +
+			"명령어를 생략하면 전체 명령어 목록을, 입력하면 그 명령어의 자세한 설명을 보여준다냥!\n\n" +
+			"💡 `/help add`",
+	}
+
+	// 피드를 직접 불러와서 비싼 명령어. 채널마다 EXPENSIVE_COMMAND_COOLDOWN 에 한 번만 쓸 수 있다
+	expensiveCommands = map[string]bool{
+		"stats-feed": true,
+		"feed-info":  true,
+	}
+
+	// 점검 모드(READONLY_MODE)에서 막는 데이터 변경 명령어. 긴급 정지용 /kill-switch 는 막지 않는다
+	mutatingCommands = map[string]bool{
+		"add":                 true,
+		ContextCommandAddFeed: true,
+		"remove":              true,
+		"note":                true,
+		"pause":               true,
+		"resume":              true,
+		"block":               true,
+		"require-category":    true,
+		"filter":              true,
+		"mirror":              true,
+		"feed-channel":        true,
+		"reorder":             true,
+		"retry-failed":        true,
+		"repair":              true,
+		"up":                  true,
+		"down":                true,
+		"delivery-mode":       true,
+		"thread-mode":         true,
+		"suppress-embeds":     true,
+		"update-notices":      true,
+		"opt-out":             true,
+		"fields":              true,
+		"post-interval":       true,
+		"burst-threshold":     true,
+		"language":            true,
+		"user-agent":          true,
+		"content":             true,
+		"min-age":             true,
+		"feed-hours":          true,
+	}
+)
+
+func verifyDiscordSignature(signature, timestamp, body string, publicKey ed25519.PublicKey) bool {
 	sig, err := hex.DecodeString(signature)
 	if err != nil {
 		log.Printf("Failed to decode signature: %v", err)
 		return false
 	}
 
+	message := timestamp + body
+	return ed25519.Verify(publicKey, []byte(message), sig)
+}
+
+func parseDiscordPublicKey(publicKey string) (ed25519.PublicKey, error) {
 	pub, err := hex.DecodeString(publicKey)
 	if err != nil {
-		log.Printf("Failed to decode public key: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to decode public key: %v", err)
 	}
 
-	message := timestamp + body
-	return ed25519.Verify(pub, []byte(message), sig)
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length: got %d bytes, want %d", len(pub), ed25519.PublicKeySize)
+	}
+
+	return ed25519.PublicKey(pub), nil
 }
 
-func connectMongoDB(ctx context.Context) (*mongo.Client, error) {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		return nil, fmt.Errorf("MONGODB_URI environment variable not set")
+func fetchSecretString(ctx context.Context, secretID string) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %v", err)
 	}
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	client := secretsmanager.NewFromConfig(cfg)
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+		return "", fmt.Errorf("failed to get secret value: %v", err)
 	}
 
-	err = client.Ping(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %v", err)
+	if output.SecretString == nil || *output.SecretString == "" {
+		return "", fmt.Errorf("secret %s has no string value", secretID)
 	}
 
-	return client, nil
+	return strings.TrimSpace(*output.SecretString), nil
 }
 
-func validateRSSFeed(url string) (*gofeed.Feed, error) {
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+func getMongoURI(ctx context.Context) (string, error) {
+	secretARN := os.Getenv("MONGODB_URI_SECRET_ARN")
+	if secretARN == "" {
+		mongoURI := os.Getenv("MONGODB_URI")
+		if mongoURI == "" {
+			return "", fmt.Errorf("MONGODB_URI environment variable not set")
+		}
+		return mongoURI, nil
 	}
 
-	fp := gofeed.NewParser()
-	fp.Client = httpClient
-	fp.UserAgent = "Mozilla/5.0 (compatible; FeedNyang/1.0; +https://github.com/nmin11/feednyang)"
+	mongoURIMu.Lock()
+	defer mongoURIMu.Unlock()
 
-	feed, err := fp.ParseURL(url)
-	if err != nil {
-		return nil, fmt.Errorf("invalid RSS feed: %v", err)
+	if cachedMongoURI != "" {
+		return cachedMongoURI, nil
 	}
 
-	if feed.Title == "" {
-		return nil, fmt.Errorf("RSS feed has no title")
+	mongoURI, err := fetchSecretString(ctx, secretARN)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch MongoDB URI from Secrets Manager: %v", err)
 	}
 
-	return feed, nil
+	cachedMongoURI = mongoURI
+	return cachedMongoURI, nil
 }
 
-func handleListCommand(ctx context.Context, channelID string) DiscordInteractionResponse {
-	client, err := connectMongoDB(ctx)
-	if err != nil {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDatabaseConnection,
-				Flags:   MessageFlagEphemeral,
-			},
+func getDiscordBotToken(ctx context.Context) (string, error) {
+	secretARN := os.Getenv("DISCORD_BOT_TOKEN_SECRET_ARN")
+	if secretARN == "" {
+		botToken := os.Getenv("DISCORD_BOT_TOKEN")
+		if botToken == "" {
+			return "", fmt.Errorf("DISCORD_BOT_TOKEN environment variable not set")
 		}
+		return botToken, nil
 	}
-	defer client.Disconnect(ctx)
 
-	channelCollection := client.Database("feednyang").Collection("discord_channels")
-	var channel DiscordChannel
+	botTokenMu.Lock()
+	defer botTokenMu.Unlock()
 
-	err = channelCollection.FindOne(ctx, bson.M{"_id": channelID}).Decode(&channel)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return DiscordInteractionResponse{
-				Type: ResponseTypeChannelMessage,
-				Data: DiscordInteractionResponseData{
-					Content: NoRegisteredFeed,
-				},
-			}
-		}
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnFeedParsing,
-				Flags:   MessageFlagEphemeral,
-			},
-		}
+	if cachedBotToken != "" {
+		return cachedBotToken, nil
 	}
 
-	if len(channel.Feeds) == 0 {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: NoRegisteredFeed,
-			},
-		}
+	botToken, err := fetchSecretString(ctx, secretARN)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Discord bot token from Secrets Manager: %v", err)
 	}
 
-	content := "📋 **등록된 피드 목록:**\n\n"
-	for i, feed := range channel.Feeds {
-		content += fmt.Sprintf("%d. **%s**\n📎 %s\n📊 전송된 포스트: %d개\n\n",
-			i+1, feed.BlogName, feed.RssURL, feed.TotalPostsSent)
-	}
+	cachedBotToken = botToken
+	return cachedBotToken, nil
+}
 
-	return DiscordInteractionResponse{
-		Type: ResponseTypeChannelMessage,
-		Data: DiscordInteractionResponseData{
-			Content: content,
-		},
+func invalidateDiscordBotToken() {
+	botTokenMu.Lock()
+	defer botTokenMu.Unlock()
+
+	cachedBotToken = ""
+}
+
+// 토큰이 교체되었을 수 있으니 인증에 실패하면 캐시를 비우고 한 번 더 시도한다.
+func callDiscordAPI(ctx context.Context, method string, path string, payload any) error {
+	statusCode, err := callDiscordAPIOnce(ctx, method, path, payload)
+
+	if statusCode == http.StatusUnauthorized && os.Getenv("DISCORD_BOT_TOKEN_SECRET_ARN") != "" {
+		log.Printf("Discord rejected the cached bot token, re-fetching it from Secrets Manager")
+		invalidateDiscordBotToken()
+		_, err = callDiscordAPIOnce(ctx, method, path, payload)
 	}
+
+	return err
 }
 
-func handleAddCommand(ctx context.Context, channelID string, feedURL string) DiscordInteractionResponse {
-	feed, err := validateRSSFeed(feedURL)
+func callDiscordAPIOnce(ctx context.Context, method string, path string, payload any) (int, error) {
+	botToken, err := getDiscordBotToken(ctx)
 	if err != nil {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: InvalidRSSFeed,
-				Flags:   MessageFlagEphemeral,
-			},
+		return 0, err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal Discord request: %v", err)
 		}
+		body = bytes.NewReader(encoded)
 	}
 
-	client, err := connectMongoDB(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, DiscordAPIBaseURL+path, body)
 	if err != nil {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDatabaseConnection,
-				Flags:   MessageFlagEphemeral,
-			},
-		}
+		return 0, fmt.Errorf("failed to create Discord request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bot "+botToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer client.Disconnect(ctx)
 
-	channelCollection := client.Database("feednyang").Collection("discord_channels")
-	var channel DiscordChannel
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call Discord API: %v", err)
+	}
+	defer resp.Body.Close()
 
-	err = channelCollection.FindOne(ctx, bson.M{"_id": channelID}).Decode(&channel)
-	if err != nil && err != mongo.ErrNoDocuments {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDatabaseConnection,
-				Flags:   MessageFlagEphemeral,
-			},
-		}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("discord API returned %s: %s", resp.Status, respBody)
 	}
 
-	for _, existingFeed := range channel.Feeds {
-		if existingFeed.RssURL == feedURL {
-			return DiscordInteractionResponse{
-				Type: ResponseTypeChannelMessage,
-				Data: DiscordInteractionResponseData{
-					Content: fmt.Sprintf("%s: **%s**", AlreadyRegisteredFeed, existingFeed.BlogName),
-					Flags:   MessageFlagEphemeral,
-				},
-			}
-		}
+	return resp.StatusCode, nil
+}
+
+// 멘션은 모두 막아서 @everyone 같은 문구가 알림을 보내지 않도록 한다.
+func postDiscordMessage(ctx context.Context, channelID string, content string) error {
+	return callDiscordAPI(ctx, http.MethodPost, "/channels/"+channelID+"/messages", DiscordMessageRequest{
+		Content:         content,
+		AllowedMentions: DiscordAllowedMentions{Parse: []string{}},
+	})
+}
+
+func checkChannelAccess(ctx context.Context, channelID string) error {
+	return callDiscordAPI(ctx, http.MethodGet, "/channels/"+channelID, nil)
+}
+
+func isTransientMongoError(err error) bool {
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, context.Canceled) {
+		return false
 	}
 
-	var lastPostLink string
-	var lastSentTime time.Time = time.Now()
-	if len(feed.Items) > 0 {
-		lastPostLink = feed.Items[0].Link
-		if feed.Items[0].PublishedParsed != nil {
-			lastSentTime = *feed.Items[0].PublishedParsed
-		}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
 	}
 
-	newFeed := Feed{
-		BlogName:       feed.Title,
-		RssURL:         feedURL,
-		AddedAt:        time.Now(),
-		LastSentTime:   lastSentTime,
-		LastPostLink:   lastPostLink,
-		TotalPostsSent: 0,
+	var labeledErr mongo.LabeledError
+	if errors.As(err, &labeledErr) {
+		return labeledErr.HasErrorLabel("TransientTransactionError") || labeledErr.HasErrorLabel("RetryableWriteError")
 	}
 
-	if err == mongo.ErrNoDocuments {
-		channel = DiscordChannel{
-			ID:        channelID,
-			Feeds:     []Feed{newFeed},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+	return false
+}
+
+// 일시적인 MongoDB 오류만 재시도하고 ErrNoDocuments 같은 오류는 그대로 돌려준다.
+func withMongoRetry(ctx context.Context, operation func() error) error {
+	var err error
+	for attempt := range MongoRetryAttempts {
+		err = operation()
+		if !isTransientMongoError(err) {
+			return err
 		}
-		_, err = channelCollection.InsertOne(ctx, channel)
-	} else {
-		channel.Feeds = append(channel.Feeds, newFeed)
-		channel.UpdatedAt = time.Now()
-		_, err = channelCollection.ReplaceOne(ctx, bson.M{"_id": channelID}, channel)
-	}
 
-	if err != nil {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnAddFeed,
-				Flags:   MessageFlagEphemeral,
-			},
+		if attempt < MongoRetryAttempts-1 {
+			waitTime := MongoRetryBaseDelay * time.Duration(1<<attempt)
+			log.Printf("Transient MongoDB error (attempt %d/%d): %v. Retrying in %v", attempt+1, MongoRetryAttempts, err, waitTime)
+			select {
+			case <-ctx.Done():
+				markMongoClientSuspect()
+				return err
+			case <-time.After(waitTime):
+			}
 		}
 	}
+	markMongoClientSuspect()
+	return err
+}
 
-	return DiscordInteractionResponse{
-		Type: ResponseTypeChannelMessage,
-		Data: DiscordInteractionResponseData{
-			Content: fmt.Sprintf("%s\n**%s**\n📎 %s", FeedSuccessfullyAdded, feed.Title, feedURL),
-		},
+type channelFinder interface {
+	FindOne(ctx context.Context, filter any, opts ...*options.FindOneOptions) *mongo.SingleResult
+}
+
+func findChannel(ctx context.Context, channelCollection channelFinder, channelID string) (DiscordChannel, error) {
+	var channel DiscordChannel
+	err := withMongoRetry(ctx, func() error {
+		return channelCollection.FindOne(ctx, bson.M{"_id": channelID}).Decode(&channel)
+	})
+	return channel, err
+}
+
+type channelUpdater interface {
+	UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+}
+
+func setChannelFields(ctx context.Context, channelCollection channelUpdater, channelID string, fields bson.M) error {
+	set := bson.M{"updatedAt": time.Now()}
+	for field, value := range fields {
+		set[field] = value
 	}
+
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx, bson.M{"_id": channelID}, bson.M{"$set": set})
+		return err
+	})
 }
 
-func handleHelpCommand() DiscordInteractionResponse {
-	return DiscordInteractionResponse{
-		Type: ResponseTypeChannelMessage,
-		Data: DiscordInteractionResponseData{
-			Content: HelpMessage,
-		},
+// 문서를 통째로 덮어쓰면 그 사이 RSS Lambda 가 저장한 진행 상태가 지워진다.
+func setFeedFields(ctx context.Context, channelCollection channelUpdater, channelID string, rssURL string, fields bson.M) error {
+	set := bson.M{"updatedAt": time.Now()}
+	for field, value := range fields {
+		set["feeds.$[f]."+field] = value
 	}
+
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx,
+			bson.M{"_id": channelID},
+			bson.M{"$set": set},
+			options.Update().SetArrayFilters(options.ArrayFilters{Filters: []any{bson.M{"f.rssUrl": rssURL}}}),
+		)
+		return err
+	})
 }
 
-func handleRemoveCommand(ctx context.Context, channelID string, feedIdentifier string) DiscordInteractionResponse {
-	client, err := connectMongoDB(ctx)
-	if err != nil {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDatabaseConnection,
-				Flags:   MessageFlagEphemeral,
-			},
-		}
+// 서버에 있는 피드를 파이프라인 업데이트로 옮기므로 그 사이 추가된 피드는 맨 뒤에 남는다.
+func reorderFeeds(ctx context.Context, channelCollection channelUpdater, channelID string, rssURLs []string) error {
+	ordered := bson.M{"$filter": bson.M{
+		"input": bson.M{"$map": bson.M{
+			"input": rssURLs,
+			"as":    "url",
+			"in": bson.M{"$arrayElemAt": bson.A{
+				bson.M{"$filter": bson.M{"input": "$feeds", "cond": bson.M{"$eq": bson.A{"$$this.rssUrl", "$$url"}}}},
+				0,
+			}},
+		}},
+		// 그 사이 삭제된 피드는 null 로 남으므로 걸러낸다
+		"cond": bson.M{"$ne": bson.A{"$$this", nil}},
+	}}
+	rest := bson.M{"$filter": bson.M{
+		"input": "$feeds",
+		"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this.rssUrl", rssURLs}}}},
+	}}
+
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx,
+			bson.M{"_id": channelID},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"feeds":     bson.M{"$concatArrays": bson.A{ordered, rest}},
+				"updatedAt": time.Now(),
+			}}}},
+		)
+		return err
+	})
+}
+
+func feedURLs(feeds []Feed) []string {
+	urls := make([]string, len(feeds))
+	for i, feed := range feeds {
+		urls[i] = feed.RssURL
 	}
-	defer client.Disconnect(ctx)
+	return urls
+}
 
-	channelCollection := client.Database("feednyang").Collection("discord_channels")
-	var channel DiscordChannel
+func pushFeed(ctx context.Context, channelCollection channelUpdater, channelID string, feed Feed) error {
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx,
+			bson.M{"_id": channelID},
+			bson.M{
+				"$push": bson.M{"feeds": feed},
+				"$set":  bson.M{"updatedAt": time.Now()},
+			},
+		)
+		return err
+	})
+}
 
-	err = channelCollection.FindOne(ctx, bson.M{"_id": channelID}).Decode(&channel)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return DiscordInteractionResponse{
-				Type: ResponseTypeChannelMessage,
-				Data: DiscordInteractionResponseData{
-					Content: NoRegisteredFeed,
-					Flags:   MessageFlagEphemeral,
-				},
-			}
-		}
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDatabaseConnection,
-				Flags:   MessageFlagEphemeral,
+func pullFeed(ctx context.Context, channelCollection channelUpdater, channelID string, rssURL string) error {
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx,
+			bson.M{"_id": channelID},
+			bson.M{
+				"$pull": bson.M{"feeds": bson.M{"rssUrl": rssURL}},
+				"$set":  bson.M{"updatedAt": time.Now()},
 			},
-		}
-	}
+		)
+		return err
+	})
+}
 
-	index := -1
-	if idx, err := strconv.Atoi(feedIdentifier); err == nil && idx > 0 && idx <= len(channel.Feeds) {
-		index = idx - 1
-	} else {
-		normalizedInput := strings.ToLower(strings.ReplaceAll(feedIdentifier, " ", ""))
-		for i, feed := range channel.Feeds {
-			normalizedBlogName := strings.ToLower(strings.ReplaceAll(feed.BlogName, " ", ""))
-			if normalizedBlogName == normalizedInput || feed.RssURL == feedIdentifier {
-				index = i
-				break
+// 캐시된 클라이언트는 Disconnect 하지 않고, 앞선 작업이 실패했을 때만 다시 Ping 한다.
+func connectMongoDB(ctx context.Context) (*mongo.Client, error) {
+	mongoClientMu.Lock()
+	cached, suspect := cachedMongoClient, mongoClientSuspect
+	mongoClientMu.Unlock()
+
+	if cached != nil && !suspect {
+		return cached, nil
+	}
+	if cached != nil {
+		err := pingMongoDB(ctx, cached)
+		if err == nil {
+			mongoClientMu.Lock()
+			if cachedMongoClient == cached {
+				mongoClientSuspect = false
 			}
+			mongoClientMu.Unlock()
+			return cached, nil
 		}
+		log.Printf("Cached MongoDB client failed to ping, reconnecting: %v", err)
 	}
 
-	if index == -1 {
-		return DiscordInteractionResponse{
-			Type: ResponseTypeChannelMessage,
-			Data: DiscordInteractionResponseData{
-				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
-				Flags:   MessageFlagEphemeral,
-			},
+	mongoURI, err := getMongoURI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	}
+
+	err = withMongoRetry(ctx, func() error {
+		return pingMongoDB(ctx, client)
+	})
+	if err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping MongoDB: %v", err)
+	}
+
+	mongoClientMu.Lock()
+	if cachedMongoClient != nil && cachedMongoClient != cached && !mongoClientSuspect {
+		// 그 사이 다른 고루틴이 먼저 새로 연결했다
+		winner := cachedMongoClient
+		mongoClientMu.Unlock()
+		client.Disconnect(ctx)
+		return winner, nil
+	}
+	cachedMongoClient = client
+	mongoClientSuspect = false
+	mongoClientMu.Unlock()
+
+	if cached != nil {
+		cached.Disconnect(ctx)
+	}
+	return client, nil
+}
+
+func markMongoClientSuspect() {
+	mongoClientMu.Lock()
+	defer mongoClientMu.Unlock()
+	if cachedMongoClient != nil {
+		mongoClientSuspect = true
+	}
+}
+
+func defaultUserAgent() string {
+	if userAgent := os.Getenv("FEED_USER_AGENT"); userAgent != "" {
+		return userAgent
+	}
+	return DefaultUserAgent
+}
+
+// file://, gopher:// 같은 스킴으로 피드를 가져오지 않도록 막는다.
+func validateFeedURL(feedURL string) error {
+	parsed, err := neturl.Parse(strings.TrimSpace(feedURL))
+	if err != nil {
+		return fmt.Errorf("invalid feed URL: %v", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported feed URL scheme: %q", parsed.Scheme)
+	}
+
+	if parsed.Hostname() == "" {
+		return fmt.Errorf("feed URL has no host")
+	}
+
+	return nil
+}
+
+// 링크 로컬에는 AWS 메타데이터 주소 169.254.169.254 도 들어간다.
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// DNS 조회 뒤의 실제 주소로 내부망 접속을 막는다 (ALLOW_PRIVATE_FEED_TARGETS=true 로 끈다).
+func newFeedDialer() *net.Dialer {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if os.Getenv("ALLOW_PRIVATE_FEED_TARGETS") == "true" {
+		return dialer
+	}
+
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("invalid dial address %s: %v", address, err)
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil || isPrivateAddress(ip) {
+			return fmt.Errorf("refusing to connect to private address %s", host)
+		}
+
+		return nil
+	}
+
+	return dialer
+}
+
+// 프록시를 거치면 다이얼러에는 프록시 주소만 보이므로 목적지는 여기서 검사한다.
+func feedProxy(proxyConfig *httpproxy.Config) func(*http.Request) (*neturl.URL, error) {
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*neturl.URL, error) {
+		proxyURL, err := proxyFunc(req.URL)
+		if err != nil || proxyURL == nil || os.Getenv("ALLOW_PRIVATE_FEED_TARGETS") == "true" {
+			return proxyURL, err
+		}
+
+		if err := checkPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}
+}
+
+func checkPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", host, err)
+	}
+
+	for _, addr := range addrs {
+		if isPrivateAddress(addr.IP) {
+			return fmt.Errorf("refusing to connect to private address %s (%s)", addr.IP, host)
+		}
+	}
+	return nil
+}
+
+// 사설망에 있는 사내 프록시로 가는 연결만 검사 없이 연다.
+func feedDialContext(proxyConfig *httpproxy.Config) func(ctx context.Context, network, address string) (net.Conn, error) {
+	proxyAddresses := make(map[string]bool)
+	for _, rawProxy := range []string{proxyConfig.HTTPProxy, proxyConfig.HTTPSProxy} {
+		if address := proxyAddress(rawProxy); address != "" {
+			proxyAddresses[address] = true
+		}
+	}
+
+	guarded := newFeedDialer()
+	direct := &net.Dialer{Timeout: guarded.Timeout}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if proxyAddresses[address] {
+			return direct.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
+
+// http.Transport 가 실제로 접속하는 host:port 로 바꾼다.
+func proxyAddress(rawProxy string) string {
+	if rawProxy == "" {
+		return ""
+	}
+
+	proxyURL, err := neturl.Parse(rawProxy)
+	if err != nil || proxyURL.Host == "" {
+		// 스킴 없이 적은 값은 httpproxy 처럼 http 프록시로 본다
+		if proxyURL, err = neturl.Parse("http://" + rawProxy); err != nil {
+			return ""
+		}
+	}
+
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// 로고가 없으면 사이트의 /favicon.ico 를 쓴다.
+func feedSiteInfo(feed *gofeed.Feed, feedURL string) (string, string) {
+	site, err := neturl.Parse(feed.Link)
+	if err != nil || (site.Scheme != "http" && site.Scheme != "https") || site.Host == "" {
+		site, err = neturl.Parse(feedURL)
+		if err != nil || site.Host == "" {
+			return "", ""
+		}
+		site = &neturl.URL{Scheme: site.Scheme, Host: site.Host}
+	}
+
+	iconURL := (&neturl.URL{Scheme: site.Scheme, Host: site.Host, Path: "/favicon.ico"}).String()
+	if feed.Image != nil && strings.HasPrefix(feed.Image.URL, "http") {
+		iconURL = feed.Image.URL
+	}
+
+	return site.String(), iconURL
+}
+
+// userAgent 가 비어 있으면 파서의 기본 User-Agent 를 쓴다.
+func fetchFeed(ctx context.Context, fp *gofeed.Parser, feedURL string, userAgent string) (*gofeed.Feed, error) {
+	if err := validateFeedURL(feedURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if userAgent == "" {
+		userAgent = fp.UserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := fp.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxFeedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed body: %v", err)
+	}
+
+	// 일부 서버는 Content-Encoding 헤더 없이 gzip 으로 압축된 본문을 내려준다
+	if bytes.HasPrefix(body, gzipMagic) {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzipped feed body: %v", err)
+		}
+		body, err = io.ReadAll(io.LimitReader(gz, MaxFeedBodySize))
+		gz.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress feed body: %v", err)
+		}
+	}
+
+	// Content-Type 은 믿지 않고 (application/octet-stream, text/plain 등) 본문 앞부분으로 피드 여부를 판단한다
+	if !looksLikeFeed(body) {
+		// 인증이 필요해진 피드는 보통 다른 호스트의 SSO 로그인 페이지로 리다이렉트되어 HTML 을 200 으로 돌려준다
+		if finalURL := resp.Request.URL; finalURL != nil && !strings.EqualFold(finalURL.Hostname(), req.URL.Hostname()) {
+			return nil, fmt.Errorf("%w: redirected to %s", errFeedLoginRequired, finalURL.Redacted())
+		}
+		return nil, fmt.Errorf("response is not a feed (Content-Type: %s)", resp.Header.Get("Content-Type"))
+	}
+
+	body, err = decodeFeedBody(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := fp.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range feed.Items {
+		item.Title = cleanTitle(item.Title)
+	}
+
+	return feed, nil
+}
+
+// gofeed 는 CDATA 안의 이스케이프된 태그를 그대로 두므로 그럴 때만 풀어서 지운다.
+func cleanTitle(title string) string {
+	if !escapedHTMLPattern.MatchString(title) {
+		return title
+	}
+
+	// &amp;lt; 처럼 한 겹 더 감싼 경우까지 푼다
+	text := html.UnescapeString(html.UnescapeString(title))
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// 선언된 charset 이 EUC-KR 같은 레거시 인코딩이면 UTF-8 로 바꾼다.
+func decodeFeedBody(body []byte, contentType string) ([]byte, error) {
+	head := body[:min(len(body), 1024)]
+
+	label := ""
+	if match := xmlEncodingPattern.FindSubmatch(head); match != nil {
+		label = string(match[1])
+	} else if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		label = params["charset"]
+	}
+	if label == "" {
+		return body, nil
+	}
+
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return body, nil
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return body, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s feed body: %v", label, err)
+	}
+
+	// gofeed 가 XML 선언의 인코딩으로 한 번 더 디코딩하지 않도록 선언도 UTF-8 로 고친다
+	if loc := xmlEncodingPattern.FindSubmatchIndex(decoded[:min(len(decoded), 1024)]); loc != nil {
+		decoded = append(append(append([]byte{}, decoded[:loc[2]]...), "UTF-8"...), decoded[loc[3]:]...)
+	}
+
+	return decoded, nil
+}
+
+// gofeed 의 공통 Feed 에는 ttl 이 없어서 Custom["ttl"] 로 남긴다.
+type ttlRSSTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *ttlRSSTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if rssFeed, ok := feed.(*rss.Feed); ok && strings.TrimSpace(rssFeed.TTL) != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom["ttl"] = strings.TrimSpace(rssFeed.TTL)
+	}
+
+	return result, nil
+}
+
+// <ttl> 이나 sy:updatePeriod 로 구한 폴링 간격(분)이다. 힌트가 없으면 0 이다.
+func feedPollInterval(feed *gofeed.Feed) int {
+	minutes := 0
+	if ttl, err := strconv.Atoi(feed.Custom["ttl"]); err == nil && ttl > 0 {
+		minutes = ttl
+	} else if sy, ok := feed.Extensions["sy"]; ok {
+		periodMinutes := map[string]int{
+			"hourly":  60,
+			"daily":   24 * 60,
+			"weekly":  7 * 24 * 60,
+			"monthly": 30 * 24 * 60,
+			"yearly":  365 * 24 * 60,
+		}
+
+		period := "daily"
+		if values := sy["updatePeriod"]; len(values) > 0 {
+			period = strings.ToLower(strings.TrimSpace(values[0].Value))
+		}
+		frequency := 1
+		if values := sy["updateFrequency"]; len(values) > 0 {
+			if parsed, err := strconv.Atoi(strings.TrimSpace(values[0].Value)); err == nil && parsed > 0 {
+				frequency = parsed
+			}
+		}
+		if periodMinutes[period] > 0 {
+			minutes = periodMinutes[period] / frequency
+		}
+	}
+
+	if minutes == 0 {
+		return 0
+	}
+	return min(max(minutes, MinPollInterval), MaxPollInterval)
+}
+
+func looksLikeFeed(body []byte) bool {
+	head := bytes.TrimPrefix(body, utf8BOM)
+	head = bytes.TrimLeft(head, " \t\r\n")
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.ToLower(head)
+
+	if bytes.HasPrefix(head, []byte("{")) {
+		return true
+	}
+
+	for _, prefix := range []string{"<?xml", "<rss", "<feed", "<rdf:rdf"} {
+		if bytes.HasPrefix(head, []byte(prefix)) {
+			return true
+		}
+	}
+
+	// 루트 엘리먼트 앞에 주석이 있는 경우
+	if bytes.HasPrefix(head, []byte("<!--")) {
+		for _, root := range []string{"<rss", "<feed", "<rdf:rdf"} {
+			if bytes.Contains(head, []byte(root)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// 인증서 검증은 ALLOW_INSECURE_TLS=true 일 때만 끈다.
+func newFeedTLSConfig() *tls.Config {
+	if os.Getenv("ALLOW_INSECURE_TLS") == "true" {
+		log.Printf("WARNING: ALLOW_INSECURE_TLS is set, feed requests skip TLS certificate verification")
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+func newFeedParser() *gofeed.Parser {
+	proxyConfig := httpproxy.FromEnvironment()
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           feedProxy(proxyConfig),
+			DialContext:     feedDialContext(proxyConfig),
+			TLSClientConfig: newFeedTLSConfig(),
+		},
+		// 리다이렉트로 다른 스킴에 접근하지 못하도록 이동할 주소도 검사한다
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return validateFeedURL(req.URL.String())
+		},
+	}
+
+	fp := gofeed.NewParser()
+	fp.Client = httpClient
+	fp.UserAgent = defaultUserAgent()
+	fp.RSSTranslator = &ttlRSSTranslator{}
+
+	return fp
+}
+
+func validateRSSFeed(ctx context.Context, url string) (*gofeed.Feed, error) {
+	feed, err := fetchFeed(ctx, newFeedParser(), url, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid RSS feed: %w", err)
+	}
+
+	if feed.Title == "" {
+		if len(feed.Items) == 0 {
+			return nil, fmt.Errorf("RSS feed has no title and no items")
+		}
+
+		// 제목이 없는 피드는 사이트 호스트를 블로그 이름으로 사용한다
+		feed.Title = feedHostName(feed.Link, url)
+		if feed.Title == "" {
+			return nil, fmt.Errorf("RSS feed has no title")
+		}
+	}
+
+	return feed, nil
+}
+
+// Medium 의 source=rss... 처럼 가져올 때마다 바뀌는 추적용 파라미터를 지운다.
+func stripTrackingParams(query neturl.Values) {
+	for key, values := range query {
+		lowerKey := strings.ToLower(key)
+		if strings.HasPrefix(lowerKey, "utm_") {
+			query.Del(key)
+			continue
+		}
+		if lowerKey == "source" && len(values) > 0 && strings.HasPrefix(values[0], "rss") {
+			query.Del(key)
+		}
+	}
+}
+
+// 예: https://d2.naver.com/d2.atom 와 http://D2.naver.com/d2.atom/ 를 같은 문자열로 만든다.
+func normalizeFeedURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	parsed, err := neturl.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	stripTrackingParams(query)
+
+	normalized := strings.ToLower(parsed.Host) + strings.TrimSuffix(parsed.Path, "/")
+	if encodedQuery := query.Encode(); encodedQuery != "" {
+		normalized += "?" + encodedQuery
+	}
+	return normalized
+}
+
+func feedHostName(candidates ...string) string {
+	for _, candidate := range candidates {
+		parsed, err := neturl.Parse(candidate)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		return strings.TrimPrefix(parsed.Hostname(), "www.")
+	}
+	return ""
+}
+
+// 화면에 보여줄 때만 쓰고, 저장하거나 비교할 때는 원래 URL 을 쓴다.
+func displayURL(u string, limit int) string {
+	if utf8.RuneCountInString(u) <= limit {
+		return u
+	}
+
+	parsed, err := neturl.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return string([]rune(u)[:limit-1]) + "…"
+	}
+
+	display := parsed.Host + parsed.EscapedPath()
+	if runes := []rune(display); len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		display += "?…"
+	}
+	return display
+}
+
+// 메시지 하나에 embed 는 10개까지라 나머지는 개수만 알려준다.
+func buildFeedListEmbeds(feeds []Feed, indexes []int) DiscordInteractionResponseData {
+	var embeds []DiscordEmbed
+	for n, i := range indexes {
+		if n == MaxEmbedsPerMessage {
+			break
+		}
+
+		feed := feeds[i]
+
+		description := fmt.Sprintf("🆔 `#%s`\n📎 %s\n📊 전송된 포스트: %d개", feedShortID(feed), displayURL(feed.RssURL, MaxDisplayURLLength), feed.TotalPostsSent)
+		if feed.Note != "" {
+			description += fmt.Sprintf("\n📝 %s", feed.Note)
+		}
+		if feed.Paused {
+			description += "\n⏸️ 일시정지됨"
+		}
+		if feed.LastError == FeedErrorLoginRequired {
+			description += "\n" + FeedRequiresLogin
+		}
+
+		embed := DiscordEmbed{
+			Title:       fmt.Sprintf("%d. %s", i+1, feed.BlogName),
+			URL:         feed.SiteURL,
+			Description: description,
+		}
+		if feed.IconURL != "" {
+			embed.Thumbnail = &DiscordEmbedThumbnail{URL: feed.IconURL}
+		}
+		embeds = append(embeds, embed)
+	}
+
+	content := "📋 **등록된 피드 목록:**"
+	if len(indexes) > MaxEmbedsPerMessage {
+		content += fmt.Sprintf("\n…외 %d개는 `/list` 로 확인하라냥!", len(indexes)-MaxEmbedsPerMessage)
+	}
+
+	return DiscordInteractionResponseData{
+		Content: content,
+		Embeds:  embeds,
+	}
+}
+
+func matchesFeedStatus(feed Feed, status string) bool {
+	switch status {
+	case FeedStatusActive:
+		return feed.ConsecutiveFailures == 0 && feed.LastError == ""
+	case FeedStatusFailing:
+		return feed.ConsecutiveFailures > 0 || feed.LastError != ""
+	case FeedStatusLoginRequired:
+		return feed.LastError == FeedErrorLoginRequired
+	default:
+		return true
+	}
+}
+
+// 번호는 전체 목록 기준이고, total 은 status 로 걸러낸 피드 수다.
+func buildFeedListContent(channel DiscordChannel, pageIndexes []int, total int, status string, urlLimit int) string {
+	content := "📋 **등록된 피드 목록:**\n\n"
+	if status != "" {
+		content = fmt.Sprintf("📋 **등록된 피드 목록 (%s, %d/%d개):**\n\n", status, total, len(channel.Feeds))
+	}
+	for _, i := range pageIndexes {
+		feed := channel.Feeds[i]
+		content += fmt.Sprintf("%d. **%s** `#%s`\n📎 %s\n📊 전송된 포스트: %d개\n",
+			i+1, feed.BlogName, feedShortID(feed), displayURL(feed.RssURL, urlLimit), feed.TotalPostsSent)
+		if feed.Note != "" {
+			content += fmt.Sprintf("📝 %s\n", feed.Note)
+		}
+		if feed.Paused {
+			content += "⏸️ 일시정지됨\n"
+		}
+		if len(feed.BlockKeywords) > 0 {
+			content += fmt.Sprintf("🚫 차단 키워드: %s\n", strings.Join(feed.BlockKeywords, ", "))
+		}
+		if len(feed.Keywords) > 0 {
+			content += fmt.Sprintf("🔍 필터 키워드: %s\n", strings.Join(feed.Keywords, ", "))
+		}
+		if len(feed.RequireCategories) > 0 {
+			content += fmt.Sprintf("🏷️ 받을 카테고리: %s\n", strings.Join(feed.RequireCategories, ", "))
+		}
+		if feed.LastError == FeedErrorLoginRequired {
+			content += FeedRequiresLogin + "\n"
+		}
+		if feed.ConsecutiveFailures > 0 {
+			content += fmt.Sprintf("⚠️ 연속 실패 횟수: %d회\n", feed.ConsecutiveFailures)
+		}
+		if len(feed.MirrorChannelIDs) > 0 {
+			mirrors := make([]string, len(feed.MirrorChannelIDs))
+			for i, mirrorChannelID := range feed.MirrorChannelIDs {
+				mirrors[i] = "<#" + mirrorChannelID + ">"
+			}
+			content += fmt.Sprintf("🪞 미러 채널: %s\n", strings.Join(mirrors, ", "))
+		}
+		if feed.OverrideChannelID != "" {
+			content += fmt.Sprintf("📮 보내는 채널: <#%s>\n", feed.OverrideChannelID)
+		}
+		content += "\n"
+	}
+
+	return content
+}
+
+func listPageFooter(page int, totalPages int) string {
+	if totalPages <= 1 {
+		return ""
+	}
+	if page < totalPages {
+		return fmt.Sprintf("📄 페이지 %d/%d · `/list page:%d` 로 다음 페이지를 보라냥!", page, totalPages, page+1)
+	}
+	return fmt.Sprintf("📄 페이지 %d/%d", page, totalPages)
+}
+
+func handleListCommand(ctx context.Context, channelID string, rich bool, status string, page int) DiscordInteractionResponse {
+	if status != "" && status != FeedStatusActive && status != FeedStatusFailing && status != FeedStatusLoginRequired {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: InvalidFeedStatus,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnFeedParsing,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if len(channel.Feeds) == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoRegisteredFeed,
+			},
+		}
+	}
+
+	// 걸러내도 번호는 전체 목록 기준으로 보여줘야 다른 명령어에 그대로 쓸 수 있다
+	var indexes []int
+	for i, feed := range channel.Feeds {
+		if matchesFeedStatus(feed, status) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(indexes) == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf(NoFeedWithStatus, status),
+			},
+		}
+	}
+
+	totalPages := (len(indexes) + FeedsPerListPage - 1) / FeedsPerListPage
+	if page < 1 || page > totalPages {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf(InvalidListPage, totalPages),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+	pageIndexes := indexes[(page-1)*FeedsPerListPage : min(page*FeedsPerListPage, len(indexes))]
+	footer := listPageFooter(page, totalPages)
+
+	if rich {
+		data := buildFeedListEmbeds(channel.Feeds, pageIndexes)
+		if footer != "" {
+			data.Content += "\n" + footer
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: data,
+		}
+	}
+
+	content := buildFeedListContent(channel, pageIndexes, len(indexes), status, MaxDisplayURLLength)
+	if utf8.RuneCountInString(content+footer) > DiscordMessageLimit {
+		// 메모나 차단 키워드가 많은 페이지는 URL 을 더 줄여서 제한 안에 맞춘다
+		content = buildFeedListContent(channel, pageIndexes, len(indexes), status, CompactDisplayURLLength)
+	}
+	if limit := DiscordMessageLimit - utf8.RuneCountInString(footer) - 1; utf8.RuneCountInString(content) > limit {
+		content = string([]rune(content)[:limit-1]) + "…\n"
+	}
+	content += footer
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+// 추가할 수 있으면 빈 문자열을 돌려준다.
+func addFeedRefusal(feeds []Feed, feedURL string) string {
+	for _, existingFeed := range feeds {
+		if normalizeFeedURL(existingFeed.RssURL) == normalizeFeedURL(feedURL) {
+			return fmt.Sprintf("%s: **%s**", AlreadyRegisteredFeed, existingFeed.BlogName)
+		}
+	}
+
+	if limit := maxFeedsPerChannel(); len(feeds) >= limit {
+		return fmt.Sprintf(TooManyFeedsInChannel, limit)
+	}
+	return ""
+}
+
+func handleAddCommand(ctx context.Context, interaction DiscordInteraction, feedURL string, sendLatest bool) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	return addFeedToChannel(ctx, channelCollection, interaction, feedURL, sendLatest)
+}
+
+type channelStore interface {
+	channelFinder
+	channelUpdater
+	InsertOne(ctx context.Context, document any, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+}
+
+// 중복이나 개수 제한은 피드를 불러오기 전에 거절한다.
+func addFeedToChannel(ctx context.Context, channelCollection channelStore, interaction DiscordInteraction, feedURL string, sendLatest bool) DiscordInteractionResponse {
+	channelID := interaction.ChannelID
+	channel, err := findChannel(ctx, channelCollection, channelID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+	isNewChannel := err == mongo.ErrNoDocuments
+
+	if refusal := addFeedRefusal(channel.Feeds, feedURL); refusal != "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: refusal,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	feed, err := validateFeed(ctx, feedURL)
+	if err != nil {
+		content := InvalidRSSFeed
+		if errors.Is(err, errFeedLoginRequired) {
+			content = FeedRequiresLogin
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: content,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	var lastPostLink string
+	var lastSentTime time.Time = time.Now()
+	if len(feed.Items) > 0 {
+		lastPostLink = feed.Items[0].Link
+		if feed.Items[0].PublishedParsed != nil {
+			lastSentTime = *feed.Items[0].PublishedParsed
+		}
+	}
+
+	siteURL, iconURL := feedSiteInfo(feed, feedURL)
+	newFeed := Feed{
+		BlogName:       feed.Title,
+		RssURL:         feedURL,
+		AddedAt:        time.Now(),
+		LastSentTime:   lastSentTime,
+		LastPostLink:   lastPostLink,
+		TotalPostsSent: 0,
+		SiteURL:        siteURL,
+		IconURL:        iconURL,
+		ShortID:        computeFeedShortID(feedURL),
+		PollInterval:   feedPollInterval(feed),
+		LastCheckedAt:  time.Now(),
+		AddedBy:        interactionUserID(interaction),
+	}
+
+	if isNewChannel {
+		channel = DiscordChannel{
+			ID:        channelID,
+			Feeds:     []Feed{newFeed},
+			CreatedBy: interactionUserID(interaction),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		_, err = channelCollection.InsertOne(ctx, channel)
+	} else {
+		err = pushFeed(ctx, channelCollection, channelID, newFeed)
+	}
+
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnAddFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := fmt.Sprintf("%s\n**%s**\n📎 %s", FeedSuccessfullyAdded, feed.Title, feedURL)
+	canPost := botCanPost(interaction)
+	if !canPost {
+		// 피드는 등록해 두고, 권한만 고치면 바로 받을 수 있도록 알려준다
+		content += "\n" + BotCannotPostInChannel
+	}
+	if sendLatest && canPost && len(feed.Items) > 0 {
+		// lastPostLink 가 이미 이 글을 가리키므로 RSS Lambda 가 같은 글을 다시 보내지 않는다
+		latest := feed.Items[0]
+		if err := postDiscordMessage(ctx, channelID, fmt.Sprintf("📝 %s\n**🚀 %s**\n🔗 %s", feed.Title, latest.Title, latest.Link)); err != nil {
+			log.Printf("Error sending latest item of %s to channel %s: %v", feedURL, channelID, err)
+			content += "\n" + ErrorOccurredOnSendLatest
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func formatDisplayTime(t time.Time) string {
+	if t.IsZero() {
+		return "없음"
+	}
+	return t.In(kst).Format(DisplayTimeLayout)
+}
+
+func buildFeedStatsContent(channel DiscordChannel, feed Feed) string {
+	content := fmt.Sprintf("📊 **%s 피드 통계다냥~**\n\n", feed.BlogName)
+	content += fmt.Sprintf("📎 %s\n", feed.RssURL)
+	content += fmt.Sprintf("🗓️ 추가된 날짜: %s\n", formatDisplayTime(feed.AddedAt))
+	// 예전에 추가된 피드나 채널은 누가 추가했는지 남아 있지 않다
+	if feed.AddedBy != "" {
+		content += fmt.Sprintf("🙋 추가한 사람: <@%s>\n", feed.AddedBy)
+	}
+	if channel.CreatedBy != "" {
+		content += fmt.Sprintf("🏠 채널에 처음 피드를 등록한 사람: <@%s>\n", channel.CreatedBy)
+	}
+	content += fmt.Sprintf("📨 전송된 포스트: %d개\n", feed.TotalPostsSent)
+	content += fmt.Sprintf("⏰ 마지막 전송: %s\n", formatDisplayTime(feed.LastSentTime))
+	content += fmt.Sprintf("⚠️ 연속 실패 횟수: %d회\n", feed.ConsecutiveFailures)
+	if feed.AvgParseMs > 0 {
+		avgParseTime := time.Duration(feed.AvgParseMs) * time.Millisecond
+		content += fmt.Sprintf("⏱️ 평균 조회 시간: %.1f초\n", avgParseTime.Seconds())
+		if avgParseTime >= SlowFeedThreshold {
+			content += SlowFeedNotice + "\n"
+		}
+	}
+	if feed.AvgDeliverySeconds > 0 {
+		content += fmt.Sprintf("🚚 평균 전송 지연: %s\n", time.Duration(feed.AvgDeliverySeconds)*time.Second)
+	}
+	if feed.LastError == FeedErrorLoginRequired {
+		content += FeedRequiresLogin + "\n"
+	}
+	return content
+}
+
+func handleStatsFeedCommand(ctx context.Context, channelID string, feedIdentifier string) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	feed := channel.Feeds[index]
+	content := buildFeedStatsContent(channel, feed)
+
+	// 최신 글은 피드를 직접 조회해서 보여준다
+	liveFeed, err := fetchFeed(ctx, newFeedParser(), feed.RssURL, feed.UserAgent)
+	switch {
+	case err != nil:
+		log.Printf("Failed to fetch feed %s for stats: %v", feed.RssURL, err)
+		content += "🆕 최신 글: 지금은 피드를 불러올 수 없다냥...\n"
+	case len(liveFeed.Items) == 0:
+		content += "🆕 최신 글: 아직 글이 없다냥~\n"
+	default:
+		item := liveFeed.Items[0]
+		var publishedAt time.Time
+		if item.PublishedParsed != nil {
+			publishedAt = *item.PublishedParsed
+		}
+		content += fmt.Sprintf("🆕 최신 글: **%s** (%s)\n🔗 %s\n", item.Title, formatDisplayTime(publishedAt), item.Link)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content:         content,
+			AllowedMentions: &DiscordAllowedMentions{Parse: []string{}},
+		},
+	}
+}
+
+func formatParsedTime(t *time.Time) string {
+	if t == nil {
+		return "없음 ❌"
+	}
+	return formatDisplayTime(*t) + " ✅"
+}
+
+func handleFeedInfoCommand(ctx context.Context, feedURL string) DiscordInteractionResponse {
+	feed, err := fetchFeed(ctx, newFeedParser(), feedURL, "")
+	if err != nil {
+		log.Printf("Failed to fetch feed %s for feed info: %v", feedURL, err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s\n```%v```", InvalidRSSFeed, err),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := "🔍 **피드 정보다냥~**\n\n"
+	content += fmt.Sprintf("📄 형식: %s %s\n", feed.FeedType, feed.FeedVersion)
+	content += fmt.Sprintf("🏷️ 제목: %s\n", feed.Title)
+	content += fmt.Sprintf("🔗 링크: %s\n", feed.Link)
+	content += fmt.Sprintf("🌐 언어: %s\n", feed.Language)
+	content += fmt.Sprintf("📚 글 개수: %d개\n", len(feed.Items))
+
+	if len(feed.Items) > 0 {
+		item := feed.Items[0]
+		content += fmt.Sprintf("\n🆕 **최신 글:** %s\n", item.Title)
+		content += fmt.Sprintf("• published: `%s` → %s\n", item.Published, formatParsedTime(item.PublishedParsed))
+		content += fmt.Sprintf("• updated: `%s` → %s\n", item.Updated, formatParsedTime(item.UpdatedParsed))
+		content += fmt.Sprintf("• guid: `%s`\n", item.GUID)
+		content += fmt.Sprintf("• link: %s\n", item.Link)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+func handleHelpCommand(topic string) DiscordInteractionResponse {
+	topic = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(topic)), "/")
+	if topic == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: HelpMessage,
+			},
+		}
+	}
+
+	help, ok := commandHelp[topic]
+	if !ok {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: UnknownHelpTopic,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: help,
+		},
+	}
+}
+
+func findFeedIndex(feeds []Feed, feedIdentifier string) int {
+	if idx, err := strconv.Atoi(feedIdentifier); err == nil && idx > 0 && idx <= len(feeds) {
+		return idx - 1
+	}
+
+	shortID := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(feedIdentifier), "#"))
+	for i, feed := range feeds {
+		if feedShortID(feed) == shortID {
+			return i
+		}
+	}
+
+	normalizedInput := normalizeFeedName(feedIdentifier)
+	for i, feed := range feeds {
+		normalizedBlogName := normalizeFeedName(feed.BlogName)
+		if normalizedBlogName == normalizedInput || normalizeFeedURL(feed.RssURL) == normalizeFeedURL(feedIdentifier) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// 순서를 바꾸거나 다른 피드를 지워도 바뀌지 않는 ID 다.
+func computeFeedShortID(feedURL string) string {
+	sum := sha256.Sum256([]byte(normalizeFeedURL(feedURL)))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// 기본 피드처럼 ShortID 없이 저장된 피드는 URL 로 바로 계산한다.
+func feedShortID(feed Feed) string {
+	if feed.ShortID != "" {
+		return feed.ShortID
+	}
+	return computeFeedShortID(feed.RssURL)
+}
+
+// findFeedIndex 와 달리 이름이 겹치는 피드를 모두 찾는다.
+func findFeedIndexesByName(feeds []Feed, feedIdentifier string) []int {
+	normalizedInput := normalizeFeedName(feedIdentifier)
+	var indexes []int
+	for i, feed := range feeds {
+		if normalizeFeedName(feed.BlogName) == normalizedInput {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func normalizeFeedName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+func levenshteinDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+// 예: "netflix" → "Netflix TechBlog"
+func findFuzzyFeedIndexes(feeds []Feed, feedIdentifier string) []int {
+	normalizedInput := normalizeFeedName(feedIdentifier)
+	if normalizedInput == "" {
+		return nil
+	}
+
+	// 너무 짧은 입력은 아무 이름과도 편집 거리가 가까우니 오타 비교에서 뺀다
+	allowTypos := utf8.RuneCountInString(normalizedInput) > MaxFuzzyFeedDistance*2
+
+	var candidates []int
+	for i, feed := range feeds {
+		normalizedBlogName := normalizeFeedName(feed.BlogName)
+		if strings.Contains(normalizedBlogName, normalizedInput) ||
+			strings.Contains(strings.ToLower(feed.RssURL), normalizedInput) ||
+			(allowTypos && levenshteinDistance(normalizedBlogName, normalizedInput) <= MaxFuzzyFeedDistance) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	return candidates
+}
+
+func addPreviewComponents(confirmCustomID string, disabled bool) []DiscordComponent {
+	return []DiscordComponent{
+		{
+			Type: ComponentTypeActionRow,
+			Components: []DiscordComponent{
+				{
+					Type:     ComponentTypeButton,
+					Style:    ButtonStylePrimary,
+					Label:    "추가",
+					CustomID: confirmCustomID,
+					Disabled: disabled,
+				},
+				{
+					Type:     ComponentTypeButton,
+					Style:    ButtonStyleSecondary,
+					Label:    "취소",
+					CustomID: AddCancelCustomID,
+					Disabled: disabled,
+				},
+			},
+		},
+	}
+}
+
+// 버튼의 custom_id 에 피드 URL 과 최신 글 전송 여부를 담아둔다.
+func handleAddPreviewCommand(ctx context.Context, interaction DiscordInteraction, feedURL string, sendLatest bool) DiscordInteractionResponse {
+	confirmCustomID := AddConfirmCustomIDPrefix + feedURL
+	if sendLatest {
+		confirmCustomID = AddConfirmLatestCustomIDPrefix + feedURL
+	}
+	if len(confirmCustomID) > MaxCustomIDLength {
+		// custom_id 에 담을 수 없을 만큼 긴 URL 은 확인 없이 바로 추가한다
+		return handleAddCommand(ctx, interaction, feedURL, sendLatest)
+	}
+
+	feed, err := validateRSSFeed(ctx, feedURL)
+	if err != nil {
+		content := InvalidRSSFeed
+		if errors.Is(err, errFeedLoginRequired) {
+			content = FeedRequiresLogin
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: content,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := fmt.Sprintf("%s\n\n🏷️ **%s**\n📎 %s\n📚 글 개수: %d개\n", AddPreviewQuestion, feed.Title, feedURL, len(feed.Items))
+	if len(feed.Items) > 0 {
+		content += fmt.Sprintf("🆕 최신 글: %s\n", feed.Items[0].Title)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content:    content,
+			Components: addPreviewComponents(confirmCustomID, false),
+		},
+	}
+}
+
+func handleAddFromMessageCommand(ctx context.Context, interaction DiscordInteraction) DiscordInteractionResponse {
+	message := interaction.Data.Resolved.Messages[interaction.Data.TargetID]
+	feedURL := messageURLPattern.FindString(message.Content)
+	// 문장 끝에 붙은 문장 부호나 마크다운 링크의 닫는 괄호는 URL 이 아니다
+	feedURL = strings.TrimRight(feedURL, ".,;:!?)]*_~`'\"")
+
+	if interaction.Data.Type != CommandTypeMessage || feedURL == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoURLInMessage,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return handleAddPreviewCommand(ctx, interaction, feedURL, false)
+}
+
+// 값으로 피드 번호를 넘기고, 조회에 실패하면 빈 후보 목록을 돌려준다.
+func handleAutocompleteInteraction(ctx context.Context, interaction DiscordInteraction) DiscordAutocompleteResponse {
+	response := DiscordAutocompleteResponse{Type: ResponseTypeAutocompleteResult}
+	response.Data.Choices = []DiscordAutocompleteChoice{}
+
+	option, ok := findOption(interaction.Data.Options, "identifier")
+	if interaction.Data.Name != "remove" || !ok || !option.Focused {
+		return response
+	}
+	query, _ := option.Value.(string)
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB for autocomplete: %v", err)
+		return response
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	channel, err := findChannel(ctx, channelCollection, interaction.ChannelID)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to find channel %s for autocomplete: %v", interaction.ChannelID, err)
+		}
+		return response
+	}
+
+	response.Data.Choices = feedAutocompleteChoices(channel.Feeds, query)
+	return response
+}
+
+func feedAutocompleteChoices(feeds []Feed, query string) []DiscordAutocompleteChoice {
+	var indexes []int
+	if strings.TrimSpace(query) == "" {
+		for i := range feeds {
+			indexes = append(indexes, i)
+		}
+	} else {
+		indexes = findFuzzyFeedIndexes(feeds, query)
+	}
+
+	choices := []DiscordAutocompleteChoice{}
+	for _, i := range indexes {
+		if len(choices) == MaxAutocompleteChoices {
+			break
+		}
+
+		name := fmt.Sprintf("%d. %s", i+1, feeds[i].BlogName)
+		if runes := []rune(name); len(runes) > MaxAutocompleteChoiceLength {
+			name = string(runes[:MaxAutocompleteChoiceLength-1]) + "…"
+		}
+		choices = append(choices, DiscordAutocompleteChoice{Name: name, Value: strconv.Itoa(i + 1)})
+	}
+
+	return choices
+}
+
+func handleComponentInteraction(ctx context.Context, interaction DiscordInteraction) DiscordInteractionResponse {
+	customID := interaction.Data.CustomID
+
+	switch {
+	case strings.HasPrefix(customID, AddConfirmCustomIDPrefix), strings.HasPrefix(customID, AddConfirmLatestCustomIDPrefix):
+		if os.Getenv("READONLY_MODE") == "true" {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ReadonlyModeNotice,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+
+		sendLatest := strings.HasPrefix(customID, AddConfirmLatestCustomIDPrefix)
+		feedURL := strings.TrimPrefix(strings.TrimPrefix(customID, AddConfirmLatestCustomIDPrefix), AddConfirmCustomIDPrefix)
+		result := handleAddCommand(ctx, interaction, feedURL, sendLatest)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeUpdateMessage,
+			Data: DiscordInteractionResponseData{
+				Content:    result.Data.Content,
+				Components: addPreviewComponents(customID, true),
+			},
+		}
+	case customID == AddCancelCustomID:
+		confirmCustomID := AddCancelCustomID + "-done"
+		return DiscordInteractionResponse{
+			Type: ResponseTypeUpdateMessage,
+			Data: DiscordInteractionResponseData{
+				Content:    AddCancelled,
+				Components: addPreviewComponents(confirmCustomID, true),
+			},
+		}
+	default:
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: UnknownCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+}
+
+func handleRemoveCommand(ctx context.Context, channelID string, feedIdentifier string) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		candidates := findFuzzyFeedIndexes(channel.Feeds, feedIdentifier)
+		switch len(candidates) {
+		case 0:
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		case 1:
+			index = candidates[0]
+		default:
+			content := AmbiguousFeed + "\n"
+			for _, candidate := range candidates {
+				content += fmt.Sprintf("\n**%d.** %s", candidate+1, channel.Feeds[candidate].BlogName)
+			}
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: content,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+	}
+
+	// 이름이 같은 피드가 여러 개면 엉뚱한 피드를 지우지 않도록 번호나 URL 로 다시 고르게 한다
+	if sameNamed := findFeedIndexesByName(channel.Feeds, feedIdentifier); len(sameNamed) > 1 && slices.Contains(sameNamed, index) {
+		content := AmbiguousFeedName + "\n"
+		for _, candidate := range sameNamed {
+			content += fmt.Sprintf("\n**%d.** %s\n📎 %s", candidate+1, channel.Feeds[candidate].BlogName, channel.Feeds[candidate].RssURL)
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: content,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	removedFeed := channel.Feeds[index]
+	err = pullFeed(ctx, channelCollection, channelID, removedFeed.RssURL)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDeleteFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**", FeedSuccessfullyDeleted, removedFeed.BlogName),
+		},
+	}
+}
+
+// 중복 확인 기준과 통계는 그대로 두고 스케줄 실행에서만 건너뛴다.
+func handlePauseCommand(ctx context.Context, channelID string, feedIdentifier string) DiscordInteractionResponse {
+	return setFeedPaused(ctx, channelID, feedIdentifier, true)
+}
+
+func handleResumeCommand(ctx context.Context, channelID string, feedIdentifier string) DiscordInteractionResponse {
+	return setFeedPaused(ctx, channelID, feedIdentifier, false)
+}
+
+func setFeedPaused(ctx context.Context, channelID string, feedIdentifier string, paused bool) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		candidates := findFuzzyFeedIndexes(channel.Feeds, feedIdentifier)
+		switch len(candidates) {
+		case 0:
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		case 1:
+			index = candidates[0]
+		default:
+			content := AmbiguousFeed + "\n"
+			for _, candidate := range candidates {
+				content += fmt.Sprintf("\n**%d.** %s", candidate+1, channel.Feeds[candidate].BlogName)
+			}
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: content,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+	}
+
+	// 이름이 같은 피드가 여러 개면 엉뚱한 피드를 멈추지 않도록 번호나 URL 로 다시 고르게 한다
+	if sameNamed := findFeedIndexesByName(channel.Feeds, feedIdentifier); len(sameNamed) > 1 && slices.Contains(sameNamed, index) {
+		content := AmbiguousFeedName + "\n"
+		for _, candidate := range sameNamed {
+			content += fmt.Sprintf("\n**%d.** %s\n📎 %s", candidate+1, channel.Feeds[candidate].BlogName, channel.Feeds[candidate].RssURL)
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: content,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if channel.Feeds[index].Paused == paused {
+		content := FeedNotPaused
+		if paused {
+			content = FeedAlreadyPaused
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**", content, channel.Feeds[index].BlogName),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.Feeds[index].Paused = paused
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"paused": paused})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := FeedSuccessfullyResumed
+	if paused {
+		content = FeedSuccessfullyPaused
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**", content, channel.Feeds[index].BlogName),
+		},
+	}
+}
+
+func handleNoteCommand(ctx context.Context, channelID string, feedIdentifier string, note string) DiscordInteractionResponse {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoteTooLong,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.Feeds[index].Note = note
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"note": note})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if note == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**", FeedNoteSuccessfullyCleared, channel.Feeds[index].BlogName),
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**\n📝 %s", FeedNoteSuccessfullyUpdated, channel.Feeds[index].BlogName, note),
+		},
+	}
+}
+
+// toggle 이면 같은 값을 다시 입력할 때 목록에서 뺀다.
+type feedListOption struct {
+	field           string
+	values          func(feed *Feed) *[]string
+	maxValues       int
+	toggle          bool
+	emptyMessage    string
+	tooLongMessage  string
+	tooManyMessage  string
+	addedMessage    string
+	existingMessage string
+}
+
+var blockKeywordOption = feedListOption{
+	field:           "blockKeywords",
+	values:          func(feed *Feed) *[]string { return &feed.BlockKeywords },
+	maxValues:       MaxBlockKeywords,
+	toggle:          true,
+	emptyMessage:    ShouldInputBlockKeyword,
+	tooLongMessage:  KeywordTooLong,
+	tooManyMessage:  TooManyBlockKeywords,
+	addedMessage:    BlockKeywordAdded,
+	existingMessage: BlockKeywordRemoved,
+}
+
+var requireCategoryOption = feedListOption{
+	field:           "requireCategories",
+	values:          func(feed *Feed) *[]string { return &feed.RequireCategories },
+	maxValues:       MaxRequireCategories,
+	toggle:          true,
+	emptyMessage:    ShouldInputRequireCategory,
+	tooLongMessage:  CategoryTooLong,
+	tooManyMessage:  TooManyRequireCategories,
+	addedMessage:    RequireCategoryAdded,
+	existingMessage: RequireCategoryRemoved,
+}
+
+var filterKeywordOption = feedListOption{
+	field:           "keywords",
+	values:          func(feed *Feed) *[]string { return &feed.Keywords },
+	maxValues:       MaxFilterKeywords,
+	emptyMessage:    ShouldInputFilterKeyword,
+	tooLongMessage:  KeywordTooLong,
+	tooManyMessage:  TooManyFilterKeywords,
+	addedMessage:    FilterKeywordAdded,
+	existingMessage: FilterKeywordAlreadyAdded,
+}
+
+func handleFeedListOptionCommand(ctx context.Context, channelID string, feedIdentifier string, value string, option feedListOption) DiscordInteractionResponse {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: option.emptyMessage,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if utf8.RuneCountInString(value) > MaxKeywordLength {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: option.tooLongMessage,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	channel, err := findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	feed := &channel.Feeds[index]
+	values := option.values(feed)
+	message := option.addedMessage
+
+	existing := slices.IndexFunc(*values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+
+	switch {
+	case existing != -1 && !option.toggle:
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s** - `%s`", option.existingMessage, feed.BlogName, value),
+			},
+		}
+	case existing != -1:
+		*values = slices.Delete(*values, existing, existing+1)
+		message = option.existingMessage
+	default:
+		if len(*values) >= option.maxValues {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: option.tooManyMessage,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		*values = append(*values, value)
+	}
+	err = setFeedFields(ctx, channelCollection, channelID, feed.RssURL, bson.M{option.field: *values})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s** - `%s`", message, feed.BlogName, value),
+		},
+	}
+}
+
+func handleBlockCommand(ctx context.Context, channelID string, feedIdentifier string, keyword string) DiscordInteractionResponse {
+	return handleFeedListOptionCommand(ctx, channelID, feedIdentifier, keyword, blockKeywordOption)
+}
+
+func handleRequireCategoryCommand(ctx context.Context, channelID string, feedIdentifier string, category string) DiscordInteractionResponse {
+	return handleFeedListOptionCommand(ctx, channelID, feedIdentifier, category, requireCategoryOption)
+}
+
+func handleFilterCommand(ctx context.Context, channelID string, feedIdentifier string, keyword string) DiscordInteractionResponse {
+	return handleFeedListOptionCommand(ctx, channelID, feedIdentifier, keyword, filterKeywordOption)
+}
+
+func handleMirrorCommand(ctx context.Context, channelID string, feedIdentifier string, mirrorChannelID string) DiscordInteractionResponse {
+	if mirrorChannelID == channelID {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: MirrorChannelIsPrimary,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	feed := &channel.Feeds[index]
+	message := MirrorChannelAdded
+
+	if existing := slices.Index(feed.MirrorChannelIDs, mirrorChannelID); existing != -1 {
+		feed.MirrorChannelIDs = slices.Delete(feed.MirrorChannelIDs, existing, existing+1)
+		message = MirrorChannelRemoved
+	} else {
+		if len(feed.MirrorChannelIDs) >= MaxMirrorChannels {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: TooManyMirrorChannels,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+
+		if err := checkChannelAccess(ctx, mirrorChannelID); err != nil {
+			log.Printf("Bot cannot access mirror channel %s: %v", mirrorChannelID, err)
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: MirrorChannelNotAccessible,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		feed.MirrorChannelIDs = append(feed.MirrorChannelIDs, mirrorChannelID)
+	}
+	err = setFeedFields(ctx, channelCollection, channelID, feed.RssURL, bson.M{"mirrorChannelIds": feed.MirrorChannelIDs})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s** → <#%s>", message, feed.BlogName, mirrorChannelID),
+		},
+	}
+}
+
+// 피드 설정과 전송 기록은 이 채널 문서에 그대로 남는다.
+func handleFeedChannelCommand(ctx context.Context, channelID string, feedIdentifier string, overrideChannelID string) DiscordInteractionResponse {
+	if overrideChannelID == channelID {
+		overrideChannelID = ""
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if overrideChannelID != "" {
+		if err := checkChannelAccess(ctx, overrideChannelID); err != nil {
+			log.Printf("Bot cannot access override channel %s: %v", overrideChannelID, err)
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: MirrorChannelNotAccessible,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+	}
+
+	feed := &channel.Feeds[index]
+	feed.OverrideChannelID = overrideChannelID
+	err = setFeedFields(ctx, channelCollection, channelID, feed.RssURL, bson.M{"overrideChannelId": overrideChannelID})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := fmt.Sprintf("%s **%s**", FeedChannelCleared, feed.BlogName)
+	if overrideChannelID != "" {
+		content = fmt.Sprintf("%s **%s** → <#%s>", FeedChannelSet, feed.BlogName, overrideChannelID)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func expensiveCommandCooldown() time.Duration {
+	value := os.Getenv("EXPENSIVE_COMMAND_COOLDOWN")
+	if value == "" {
+		return DefaultExpensiveCommandCooldown
+	}
+
+	cooldown, err := time.ParseDuration(value)
+	if err != nil || cooldown < 0 {
+		log.Printf("Invalid EXPENSIVE_COMMAND_COOLDOWN %q, using default %v", value, DefaultExpensiveCommandCooldown)
+		return DefaultExpensiveCommandCooldown
+	}
+
+	return cooldown
+}
+
+// 피드가 너무 많으면 스케줄 실행이 길어지고 레이트 리밋에 걸리기 쉽다.
+func maxFeedsPerChannel() int {
+	value := os.Getenv("MAX_FEEDS_PER_CHANNEL")
+	if value == "" {
+		return DefaultMaxFeedsPerChannel
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		log.Printf("Invalid MAX_FEEDS_PER_CHANNEL %q, using default %d", value, DefaultMaxFeedsPerChannel)
+		return DefaultMaxFeedsPerChannel
+	}
+
+	return limit
+}
+
+// 채널 문서가 없거나 MongoDB 에 닿지 않으면 막지 않는다.
+func checkExpensiveCommandCooldown(ctx context.Context, channelID string) time.Duration {
+	cooldown := expensiveCommandCooldown()
+	if cooldown == 0 {
+		return 0
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		log.Printf("Failed to connect to MongoDB for command cooldown: %v", err)
+		return 0
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	claimed, err := claimExpensiveCommand(ctx, channelCollection, channelID, cooldown, time.Now())
+	if err != nil {
+		log.Printf("Failed to record command cooldown for channel %s: %v", channelID, err)
+		return 0
+	}
+	if claimed {
+		return 0
+	}
+
+	channel, err := findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to read channel %s for command cooldown: %v", channelID, err)
+		}
+		return 0
+	}
+	return max(time.Until(channel.LastExpensiveOpAt.Add(cooldown)), 0)
+}
+
+// 동시에 들어온 요청 중 하나만 lastExpensiveOpAt 을 바꾼다.
+func claimExpensiveCommand(ctx context.Context, channelCollection channelUpdater, channelID string, cooldown time.Duration, now time.Time) (bool, error) {
+	filter := bson.M{
+		"_id": channelID,
+		"$or": bson.A{
+			bson.M{"lastExpensiveOpAt": bson.M{"$exists": false}},
+			bson.M{"lastExpensiveOpAt": bson.M{"$lte": now.Add(-cooldown)}},
+		},
+	}
+	result, err := channelCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lastExpensiveOpAt": now}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func interactionUserID(interaction DiscordInteraction) string {
+	if interaction.Member.User.ID != "" {
+		return interaction.Member.User.ID
+	}
+	return interaction.User.ID
+}
+
+// 선택 옵션은 빠지거나 순서가 바뀌어 올 수 있어서 위치로 찾지 않는다.
+func findOption(options []DiscordInteractionDataOption, name string) (DiscordInteractionDataOption, bool) {
+	for _, option := range options {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return DiscordInteractionDataOption{}, false
+}
+
+func stringOption(options []DiscordInteractionDataOption, name string) (string, bool) {
+	option, ok := findOption(options, name)
+	if !ok {
+		return "", false
+	}
+
+	value, ok := option.Value.(string)
+	return value, ok
+}
+
+// JSON 숫자는 float64 로 풀린다.
+func intOption(options []DiscordInteractionDataOption, name string) (int, bool) {
+	option, ok := findOption(options, name)
+	if !ok {
+		return 0, false
+	}
+
+	switch value := option.Value.(type) {
+	case float64:
+		return int(value), true
+	case string:
+		parsed, err := strconv.Atoi(value)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func boolOption(options []DiscordInteractionDataOption, name string) (bool, bool) {
+	option, ok := findOption(options, name)
+	if !ok {
+		return false, false
+	}
+
+	value, ok := option.Value.(bool)
+	return value, ok
+}
+
+func isOwner(userID string) bool {
+	if userID == "" {
+		return false
+	}
+
+	for ownerID := range strings.SplitSeq(os.Getenv("OWNER_USER_IDS"), ",") {
+		if strings.TrimSpace(ownerID) == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasManagePermission(interaction DiscordInteraction) bool {
+	permissions, err := strconv.ParseUint(interaction.Member.Permissions, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return permissions&(PermissionAdministrator|PermissionManageGuild|PermissionManageChannels) != 0
+}
+
+// 권한 정보가 없거나 읽을 수 없으면 보낼 수 있다고 본다.
+func botCanPost(interaction DiscordInteraction) bool {
+	if interaction.AppPermissions == "" {
+		return true
+	}
+	permissions, err := strconv.ParseUint(interaction.AppPermissions, 10, 64)
+	if err != nil {
+		return true
+	}
+	if permissions&PermissionAdministrator != 0 {
+		return true
+	}
+
+	required := uint64(PermissionViewChannel | PermissionSendMessages)
+	return permissions&required == required
+}
+
+func handlePingPostCommand(ctx context.Context, interaction DiscordInteraction, message string) DiscordInteractionResponse {
+	if !hasManagePermission(interaction) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ManagerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputPingPost,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if utf8.RuneCountInString(message) > DiscordMessageLimit {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: PingPostTooLong,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	err := postDiscordMessage(ctx, interaction.ChannelID, message)
+	if err != nil {
+		log.Printf("Failed to send ping post to channel %s: %v", interaction.ChannelID, err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnPingPost,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: PingPostSent,
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+func handleKillSwitchCommand(ctx context.Context, userID string, state string) DiscordInteractionResponse {
+	if !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: OwnerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	paused := state == "on"
+	configCollection := client.Database("feednyang").Collection("bot_config")
+	_, err = configCollection.UpdateOne(ctx,
+		bson.M{"_id": "global"},
+		bson.M{"$set": bson.M{"paused": paused, "updatedAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Printf("Failed to update global pause state: %v", err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	log.Printf("Global pause set to %v by %s", paused, userID)
+
+	content := GlobalPauseDisabled
+	if paused {
+		content = GlobalPauseEnabled
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+func collectBotMetrics(ctx context.Context, client *mongo.Client) (BotMetrics, error) {
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$facet", Value: bson.M{
+			"channels": bson.A{
+				bson.M{"$count": "count"},
+			},
+			"feeds": bson.A{
+				bson.M{"$unwind": "$feeds"},
+				bson.M{"$group": bson.M{
+					"_id":            nil,
+					"totalFeeds":     bson.M{"$sum": 1},
+					"totalPostsSent": bson.M{"$sum": "$feeds.totalPostsSent"},
+					"failingFeeds": bson.M{"$sum": bson.M{
+						"$cond": bson.A{bson.M{"$gt": bson.A{"$feeds.consecutiveFailures", 0}}, 1, 0},
+					}},
+					"feedFailures": bson.M{"$sum": "$feeds.consecutiveFailures"},
+				}},
+			},
+			"topFeeds": bson.A{
+				bson.M{"$unwind": "$feeds"},
+				bson.M{"$group": bson.M{
+					"_id":       "$feeds.rssUrl",
+					"blogName":  bson.M{"$first": "$feeds.blogName"},
+					"postsSent": bson.M{"$sum": "$feeds.totalPostsSent"},
+					"channels":  bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"postsSent": -1}},
+				bson.M{"$limit": 5},
+			},
+		}}},
+	}
+
+	cursor, err := channelCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return BotMetrics{}, fmt.Errorf("failed to aggregate metrics: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Channels []struct {
+			Count int `bson:"count"`
+		} `bson:"channels"`
+		Feeds []struct {
+			TotalFeeds     int `bson:"totalFeeds"`
+			TotalPostsSent int `bson:"totalPostsSent"`
+			FailingFeeds   int `bson:"failingFeeds"`
+			FeedFailures   int `bson:"feedFailures"`
+		} `bson:"feeds"`
+		TopFeeds []FeedMetric `bson:"topFeeds"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return BotMetrics{}, fmt.Errorf("failed to decode metrics: %v", err)
+	}
+
+	var metrics BotMetrics
+	if len(results) == 0 {
+		return metrics, nil
+	}
+
+	result := results[0]
+	if len(result.Channels) > 0 {
+		metrics.TotalChannels = result.Channels[0].Count
+	}
+	if len(result.Feeds) > 0 {
+		metrics.TotalFeeds = result.Feeds[0].TotalFeeds
+		metrics.TotalPostsSent = result.Feeds[0].TotalPostsSent
+		metrics.FailingFeeds = result.Feeds[0].FailingFeeds
+		metrics.FeedFailures = result.Feeds[0].FeedFailures
+	}
+	metrics.TopFeeds = result.TopFeeds
+
+	return metrics, nil
+}
+
+func handleMetricsDumpCommand(ctx context.Context, userID string) DiscordInteractionResponse {
+	if !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: OwnerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	metrics, err := collectBotMetrics(ctx, client)
+	if err != nil {
+		log.Printf("Failed to collect metrics: %v", err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := "📈 **피드냥 메트릭이다냥~**\n```\n"
+	content += fmt.Sprintf("channels        %d\n", metrics.TotalChannels)
+	content += fmt.Sprintf("feeds           %d\n", metrics.TotalFeeds)
+	content += fmt.Sprintf("posts_sent      %d\n", metrics.TotalPostsSent)
+	content += fmt.Sprintf("failing_feeds   %d\n", metrics.FailingFeeds)
+	if len(metrics.TopFeeds) > 0 {
+		content += "\ntop feeds (posts sent / channels)\n"
+		for i, feed := range metrics.TopFeeds {
+			content += fmt.Sprintf("%d. %s  %d / %d\n", i+1, feed.BlogName, feed.PostsSent, feed.Channels)
+		}
+	}
+	content += "```"
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+type ChannelStats struct {
+	TotalFeeds     int
+	TotalPostsSent int
+	// MostActive, Oldest, Newest 는 해당하는 피드가 없으면 -1 이다
+	MostActive  int
+	Oldest      int
+	Newest      int
+	PausedFeeds []string
+}
+
+// 추가 시각이 없는 예전 피드는 가장 오래된 / 최근 피드에서 뺀다.
+func computeChannelStats(channel DiscordChannel) ChannelStats {
+	stats := ChannelStats{TotalFeeds: len(channel.Feeds), MostActive: -1, Oldest: -1, Newest: -1}
+	for i, feed := range channel.Feeds {
+		stats.TotalPostsSent += feed.TotalPostsSent
+		if feed.TotalPostsSent > 0 && (stats.MostActive == -1 || feed.TotalPostsSent > channel.Feeds[stats.MostActive].TotalPostsSent) {
+			stats.MostActive = i
+		}
+		if feed.Paused {
+			stats.PausedFeeds = append(stats.PausedFeeds, feed.BlogName)
+		}
+
+		if feed.AddedAt.IsZero() {
+			continue
+		}
+		if stats.Oldest == -1 || feed.AddedAt.Before(channel.Feeds[stats.Oldest].AddedAt) {
+			stats.Oldest = i
+		}
+		if stats.Newest == -1 || feed.AddedAt.After(channel.Feeds[stats.Newest].AddedAt) {
+			stats.Newest = i
+		}
+	}
+
+	return stats
+}
+
+func handleStatsCommand(ctx context.Context, channelID string) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	channel, err := findChannel(ctx, channelCollection, channelID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if err == mongo.ErrNoDocuments || len(channel.Feeds) == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoRegisteredFeed,
+			},
+		}
+	}
+
+	stats := computeChannelStats(channel)
+	content := fmt.Sprintf("📊 **이 채널의 피드 현황이다냥~**\n\n📚 피드: %d개\n📬 보낸 글: %d개\n", stats.TotalFeeds, stats.TotalPostsSent)
+	if stats.MostActive != -1 {
+		feed := channel.Feeds[stats.MostActive]
+		content += fmt.Sprintf("🥇 가장 활발한 피드: **%s** (%d개)\n", feed.BlogName, feed.TotalPostsSent)
+	} else {
+		content += "🥇 가장 활발한 피드: 아직 보낸 글이 없다냥\n"
+	}
+	if stats.Oldest != -1 {
+		feed := channel.Feeds[stats.Oldest]
+		content += fmt.Sprintf("🕰️ 가장 오래된 피드: **%s** (%s 추가)\n", feed.BlogName, feed.AddedAt.In(kst).Format("2006-01-02"))
+	}
+	if stats.Newest != -1 && stats.Newest != stats.Oldest {
+		feed := channel.Feeds[stats.Newest]
+		content += fmt.Sprintf("🆕 가장 최근 피드: **%s** (%s 추가)\n", feed.BlogName, feed.AddedAt.In(kst).Format("2006-01-02"))
+	}
+	if len(stats.PausedFeeds) > 0 {
+		content += fmt.Sprintf("⏸️ 일시정지된 피드 %d개: %s\n", len(stats.PausedFeeds), strings.Join(stats.PausedFeeds, ", "))
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+type ChannelRank struct {
+	Rank           int
+	TotalChannels  int
+	TotalPostsSent int
+}
+
+// 합계가 같은 채널은 같은 순위로 본다.
+func findChannelRank(ctx context.Context, client *mongo.Client, channelID string) (ChannelRank, error) {
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{
+			"total": bson.M{"$sum": "$feeds.totalPostsSent"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := channelCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return ChannelRank{}, fmt.Errorf("failed to aggregate channel totals: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		ID    string `bson:"_id"`
+		Total int    `bson:"total"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return ChannelRank{}, fmt.Errorf("failed to decode channel totals: %v", err)
+	}
+
+	for i, channel := range totals {
+		if channel.ID != channelID {
+			continue
+		}
+
+		rank := i + 1
+		for rank > 1 && totals[rank-2].Total == channel.Total {
+			rank--
+		}
+		return ChannelRank{Rank: rank, TotalChannels: len(totals), TotalPostsSent: channel.Total}, nil
+	}
+
+	return ChannelRank{}, mongo.ErrNoDocuments
+}
+
+// 다른 채널의 정보는 드러내지 않는다.
+func handleRankCommand(ctx context.Context, channelID string, userID string) DiscordInteractionResponse {
+	if os.Getenv("RANK_COMMAND_ENABLED") != "true" && !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: RankCommandDisabled,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	rank, err := findChannelRank(ctx, client, channelID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("Failed to find rank of channel %s: %v", channelID, err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if err == mongo.ErrNoDocuments || rank.TotalPostsSent == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoRankForChannel,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	percentile := float64(rank.Rank) / float64(rank.TotalChannels) * 100
+	content := fmt.Sprintf("🏆 **이 채널의 순위다냥~**\n\n📬 받은 글: %d개\n🥇 순위: %d위 / %d개 채널\n📊 상위 %.1f%%",
+		rank.TotalPostsSent, rank.Rank, rank.TotalChannels, percentile)
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+// feed_failures_total 은 연속 실패 횟수의 합이라 피드가 복구되면 줄어든다.
+func renderPrometheusMetrics(metrics BotMetrics) string {
+	var builder strings.Builder
+	writeMetric := func(name, metricType, help string, value int) {
+		fmt.Fprintf(&builder, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&builder, "# TYPE %s %s\n", name, metricType)
+		fmt.Fprintf(&builder, "%s %d\n", name, value)
+	}
+
+	writeMetric("feednyang_channels_total", "gauge", "Number of registered Discord channels.", metrics.TotalChannels)
+	writeMetric("feednyang_feeds_total", "gauge", "Number of registered feeds across all channels.", metrics.TotalFeeds)
+	writeMetric("feednyang_posts_sent_total", "counter", "Number of posts sent to Discord across all feeds.", metrics.TotalPostsSent)
+	writeMetric("feednyang_feed_failures_total", "gauge", "Sum of consecutive fetch failures across all feeds.", metrics.FeedFailures)
+	writeMetric("feednyang_failing_feeds", "gauge", "Number of feeds whose last fetch failed.", metrics.FailingFeeds)
+
+	return builder.String()
+}
+
+// 인터랙션 응답 시간(3초) 안에 끝나도록 한 번에 MaxRetryFailedSends 개만 보낸다.
+func handleRetryFailedCommand(ctx context.Context, userID string) DiscordInteractionResponse {
+	if !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: OwnerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	collection := client.Database("feednyang").Collection("failed_sends")
+	var failedSends []FailedSend
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "failedAt", Value: -1}}).SetLimit(MaxRetryFailedSends)
+	cursor, err := collection.Find(ctx, bson.M{}, findOptions)
+	if err == nil {
+		err = cursor.All(ctx, &failedSends)
+	}
+	if err != nil {
+		log.Printf("Failed to load failed sends: %v", err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if len(failedSends) == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NoFailedSends,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	retried, failed := 0, 0
+	for _, failedSend := range failedSends {
+		request := DiscordMessageRequest{
+			Content:         failedSend.Content,
+			AllowedMentions: DiscordAllowedMentions{Parse: []string{}},
+		}
+		if failedSend.SuppressEmbeds {
+			request.Flags = MessageFlagSuppressEmbeds
+		}
+
+		sendErr := callDiscordAPI(ctx, http.MethodPost, "/channels/"+failedSend.ChannelID+"/messages", request)
+		if sendErr != nil {
+			log.Printf("Failed to resend item %s to channel %s: %v", failedSend.ItemTitle, failedSend.ChannelID, sendErr)
+			if _, err := collection.UpdateByID(ctx, failedSend.ID, bson.M{"$set": bson.M{"error": sendErr.Error()}}); err != nil {
+				log.Printf("Failed to update failed send %s: %v", failedSend.ID.Hex(), err)
+			}
+			failed++
+			continue
+		}
+
+		if _, err := collection.DeleteOne(ctx, bson.M{"_id": failedSend.ID}); err != nil {
+			log.Printf("Failed to delete failed send %s: %v", failedSend.ID.Hex(), err)
+		}
+		retried++
+	}
+
+	remaining, err := collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		log.Printf("Failed to count failed sends: %v", err)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf(FailedSendsRetried, retried, failed, remaining),
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+// 제목이 같은 글이 없으면 가장 최신 글의 링크를 쓴다.
+func repairedLastPostLink(feed Feed, parsed *gofeed.Feed) string {
+	if legacyTitle := strings.TrimSpace(cleanTitle(feed.LastPostTitle)); legacyTitle != "" {
+		for _, item := range parsed.Items {
+			if strings.EqualFold(strings.TrimSpace(item.Title), legacyTitle) {
+				return item.Link
+			}
+		}
+	}
+
+	if len(parsed.Items) > 0 {
+		return parsed.Items[0].Link
+	}
+	return ""
+}
+
+func handleRepairCommand(ctx context.Context, userID string) DiscordInteractionResponse {
+	if !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: OwnerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channels []DiscordChannel
+	cursor, err := channelCollection.Find(ctx, bson.M{"feeds": bson.M{"$elemMatch": bson.M{"lastPostLink": bson.M{"$in": bson.A{nil, ""}}}}})
+	if err == nil {
+		err = cursor.All(ctx, &channels)
+	}
+	if err != nil {
+		log.Printf("Failed to load channels to repair: %v", err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	fp := newFeedParser()
+	repaired, failed, remaining := 0, 0, 0
+	for _, channel := range channels {
+		for _, feed := range channel.Feeds {
+			if feed.LastPostLink != "" {
+				continue
+			}
+			if repaired+failed == MaxRepairFeeds {
+				remaining++
+				continue
+			}
+
+			parsed, err := fetchFeed(ctx, fp, feed.RssURL, feed.UserAgent)
+			if err != nil {
+				log.Printf("Failed to fetch feed %s in channel %s for repair: %v", feed.RssURL, channel.ID, err)
+				failed++
+				continue
+			}
+			link := repairedLastPostLink(feed, parsed)
+			if link == "" {
+				log.Printf("Feed %s in channel %s has no items to repair from", feed.RssURL, channel.ID)
+				failed++
+				continue
+			}
+			if err := setFeedFields(ctx, channelCollection, channel.ID, feed.RssURL, bson.M{"lastPostLink": link}); err != nil {
+				log.Printf("Failed to repair feed %s in channel %s: %v", feed.RssURL, channel.ID, err)
+				failed++
+				continue
+			}
+			repaired++
+		}
+	}
+
+	if repaired+failed+remaining == 0 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: NothingToRepair,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf(FeedsRepaired, repaired, failed, remaining),
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+func handlePrometheusCommand(ctx context.Context, userID string) DiscordInteractionResponse {
+	if !isOwner(userID) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: OwnerOnlyCommand,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	metrics, err := collectBotMetrics(ctx, client)
+	if err != nil {
+		log.Printf("Failed to collect metrics: %v", err)
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: "```\n" + renderPrometheusMetrics(metrics) + "```",
+			Flags:   MessageFlagEphemeral,
+		},
+	}
+}
+
+// from, to 는 0-based 다.
+func moveFeed(feeds []Feed, from int, to int) []Feed {
+	feed := feeds[from]
+	feeds = slices.Delete(feeds, from, from+1)
+	return slices.Insert(feeds, to, feed)
+}
+
+func handleReorderCommand(ctx context.Context, channelID string, from int, to int) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if from < 1 || from > len(channel.Feeds) || to < 1 || to > len(channel.Feeds) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf(InvalidFeedPosition, len(channel.Feeds)),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	movedFeed := channel.Feeds[from-1]
+	channel.Feeds = moveFeed(channel.Feeds, from-1, to-1)
+	err = reorderFeeds(ctx, channelCollection, channelID, feedURLs(channel.Feeds))
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**: %d번 → %d번", FeedSuccessfullyMoved, movedFeed.BlogName, from, to),
+		},
+	}
+}
+
+// 이미 끝에 있으면 저장하지 않는다.
+func handleNudgeFeedCommand(ctx context.Context, channelID string, feedIdentifier string, offset int) DiscordInteractionResponse {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	target := index + offset
+	if target < 0 || target >= len(channel.Feeds) {
+		content := FeedAlreadyAtBottom
+		if target < 0 {
+			content = FeedAlreadyAtTop
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**: %d번", content, channel.Feeds[index].BlogName, index+1),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	movedFeed := channel.Feeds[index]
+	channel.Feeds = moveFeed(channel.Feeds, index, target)
+	err = reorderFeeds(ctx, channelCollection, channelID, feedURLs(channel.Feeds))
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**: %d번 → %d번", FeedSuccessfullyMoved, movedFeed.BlogName, index+1, target+1),
+		},
+	}
+}
+
+func handleDeliveryModeCommand(ctx context.Context, channelID string, mode string) DiscordInteractionResponse {
+	if mode != DeliveryModeItem && mode != DeliveryModeSummary {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputDeliveryMode,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.DeliveryMode = mode
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"deliveryMode": channel.DeliveryMode})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := DeliveryModeChangedToItem
+	if mode == DeliveryModeSummary {
+		content = DeliveryModeChangedToSummary
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleThreadModeCommand(ctx context.Context, channelID string, state string) DiscordInteractionResponse {
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	// 저장해둔 스레드 ID 는 지우지 않는다. 다시 켜면 기존 스레드를 이어서 쓴다
+	channel.ThreadMode = state == "on"
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"threadMode": channel.ThreadMode})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := ThreadModeDisabled
+	if channel.ThreadMode {
+		content = ThreadModeEnabled
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleSuppressEmbedsCommand(ctx context.Context, channelID string, state string) DiscordInteractionResponse {
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.SuppressEmbeds = state == "on"
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"suppressEmbeds": channel.SuppressEmbeds})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := SuppressEmbedsDisabled
+	if channel.SuppressEmbeds {
+		content = SuppressEmbedsEnabled
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleUpdateNoticesCommand(ctx context.Context, channelID string, state string) DiscordInteractionResponse {
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.UpdateNotices = state == "on"
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"updateNotices": channel.UpdateNotices})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := UpdateNoticesDisabled
+	if channel.UpdateNotices {
+		content = UpdateNoticesEnabled
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+// 이미 등록된 피드는 건드리지 않는다.
+func handleOptOutCommand(ctx context.Context, channelID string, state string) DiscordInteractionResponse {
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.FollowsDefaults = state == "off"
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"followsDefaults": channel.FollowsDefaults})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := DefaultFeedsOptedOut
+	if channel.FollowsDefaults {
+		content = DefaultFeedsFollowed
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+// "default" 면 nil 을 돌려줘서 기본값(제목, 링크)을 쓰게 한다.
+func parseDisplayFields(input string) ([]string, error) {
+	tokens := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(tokens) == 1 && tokens[0] == "default" {
+		return nil, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, token := range tokens {
+		if _, ok := displayFieldNames[token]; !ok {
+			return nil, fmt.Errorf("unknown display field: %s", token)
+		}
+		selected[token] = true
+	}
+
+	fields := []string{DisplayFieldTitle}
+	for _, field := range displayFieldOrder[1:] {
+		if selected[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+func handleFieldsCommand(ctx context.Context, channelID string, input string) DiscordInteractionResponse {
+	fields, err := parseDisplayFields(input)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: InvalidDisplayField,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.DisplayFields = fields
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"displayFields": channel.DisplayFields})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if fields == nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: DisplayFieldsReset,
+			},
+		}
+	}
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = displayFieldNames[field]
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s (%s)", DisplayFieldsUpdated, strings.Join(names, ", ")),
+		},
+	}
+}
+
+func handlePostIntervalCommand(ctx context.Context, channelID string, seconds int) DiscordInteractionResponse {
+	if seconds < 0 || seconds > MaxMinPostInterval {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputPostInterval,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.MinPostInterval = seconds
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"minPostInterval": channel.MinPostInterval})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := MinPostIntervalDisabled
+	if seconds > 0 {
+		content = fmt.Sprintf(MinPostIntervalUpdated, seconds)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleBurstThresholdCommand(ctx context.Context, channelID string, threshold int) DiscordInteractionResponse {
+	if threshold != 0 && (threshold < BurstPreviewCount || threshold > MaxBurstThreshold) {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputBurstThreshold,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.BurstThreshold = threshold
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"burstThreshold": channel.BurstThreshold})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := BurstThresholdDisabled
+	if threshold > 0 {
+		content = fmt.Sprintf(BurstThresholdUpdated, threshold, BurstPreviewCount)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleLanguageCommand(ctx context.Context, channelID string, languageFilter string) DiscordInteractionResponse {
+	if languageFilter != LanguageFilterKorean && languageFilter != LanguageFilterNonKorean && languageFilter != LanguageFilterOff {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputLanguageFilter,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.LanguageFilter = languageFilter
+	if languageFilter == LanguageFilterOff {
+		channel.LanguageFilter = ""
+	}
+	err = setChannelFields(ctx, channelCollection, channelID, bson.M{"languageFilter": channel.LanguageFilter})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := LanguageFilterDisabled
+	switch languageFilter {
+	case LanguageFilterKorean:
+		content = LanguageFilterSetToKorean
+	case LanguageFilterNonKorean:
+		content = LanguageFilterSetToNonKorean
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+func handleUserAgentCommand(ctx context.Context, channelID string, feedIdentifier string, userAgent string) DiscordInteractionResponse {
+	userAgent = strings.TrimSpace(userAgent)
+	if utf8.RuneCountInString(userAgent) > MaxUserAgentLength {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: UserAgentTooLong,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
 		}
 	}
 
-	removedFeed := channel.Feeds[index]
-	channel.Feeds = append(channel.Feeds[:index], channel.Feeds[index+1:]...)
-	channel.UpdatedAt = time.Now()
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
 
-	_, err = channelCollection.ReplaceOne(ctx, bson.M{"_id": channelID}, channel)
+	channel, err = findChannel(ctx, channelCollection, channelID)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
 		return DiscordInteractionResponse{
 			Type: ResponseTypeChannelMessage,
 			Data: DiscordInteractionResponseData{
-				Content: ErrorOccurredOnDeleteFeed,
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.Feeds[index].UserAgent = userAgent
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"userAgent": userAgent})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if userAgent == "" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**", UserAgentSuccessfullyReset, channel.Feeds[index].BlogName),
+			},
+		}
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**\n`%s`", UserAgentSuccessfullyUpdated, channel.Feeds[index].BlogName, userAgent),
+		},
+	}
+}
+
+func handleContentCommand(ctx context.Context, channelID string, feedIdentifier string, state string) DiscordInteractionResponse {
+	if state != "on" && state != "off" {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputOnOff,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.Feeds[index].IncludeContent = state == "on"
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"includeContent": channel.Feeds[index].IncludeContent})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := IncludeContentDisabled
+	if channel.Feeds[index].IncludeContent {
+		content = IncludeContentEnabled
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**", content, channel.Feeds[index].BlogName),
+		},
+	}
+}
+
+func handleMinAgeCommand(ctx context.Context, channelID string, feedIdentifier string, minutes int) DiscordInteractionResponse {
+	if minutes < 0 || minutes > MaxMinItemAge {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputMinItemAge,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channel.Feeds[index].MinItemAge = minutes
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"minItemAge": minutes})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	content := MinItemAgeDisabled
+	if minutes > 0 {
+		content = fmt.Sprintf(MinItemAgeUpdated, minutes)
+	}
+
+	return DiscordInteractionResponse{
+		Type: ResponseTypeChannelMessage,
+		Data: DiscordInteractionResponseData{
+			Content: fmt.Sprintf("%s **%s**", content, channel.Feeds[index].BlogName),
+		},
+	}
+}
+
+// 시간대는 KST 기준이고 끝 시각은 포함하지 않는다. 시작과 끝이 같으면 해제한다.
+func handleFeedHoursCommand(ctx context.Context, channelID string, feedIdentifier string, start int, end int) DiscordInteractionResponse {
+	if start < 0 || start > 23 || end < 0 || end > 23 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ShouldInputFeedHours,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+	var channel DiscordChannel
+
+	channel, err = findChannel(ctx, channelCollection, channelID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: NoRegisteredFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		}
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnDatabaseConnection,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	index := findFeedIndex(channel.Feeds, feedIdentifier)
+	if index == -1 {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: fmt.Sprintf("%s **%s**\n`/list` 명령어로 피드 번호 / 이름 / URL 을 확인하라냥!", FeedNotFound, feedIdentifier),
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+	}
+
+	if start == end {
+		start, end = 0, 0
+	}
+	channel.Feeds[index].ActiveHoursStart = start
+	channel.Feeds[index].ActiveHoursEnd = end
+	err = setFeedFields(ctx, channelCollection, channelID, channel.Feeds[index].RssURL, bson.M{"activeHoursStart": start, "activeHoursEnd": end})
+	if err != nil {
+		return DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ErrorOccurredOnUpdateFeed,
 				Flags:   MessageFlagEphemeral,
 			},
 		}
 	}
 
+	content := fmt.Sprintf(FeedHoursUpdated, start, end)
+	if start == end {
+		content = FeedHoursCleared
+	}
+
 	return DiscordInteractionResponse{
 		Type: ResponseTypeChannelMessage,
 		Data: DiscordInteractionResponseData{
-			Content: fmt.Sprintf("%s **%s**", FeedSuccessfullyDeleted, removedFeed.BlogName),
+			Content: fmt.Sprintf("%s **%s**", content, channel.Feeds[index].BlogName),
 		},
 	}
 }
 
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	publicKey := os.Getenv("DISCORD_PUBLIC_KEY")
-	if publicKey != "" {
+	if discordPublicKey != nil {
 		signature := request.Headers["x-signature-ed25519"]
 		timestamp := request.Headers["x-signature-timestamp"]
 
@@ -430,7 +5212,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 			}, nil
 		}
 
-		if !verifyDiscordSignature(signature, timestamp, request.Body, publicKey) {
+		if !verifyDiscordSignature(signature, timestamp, request.Body, discordPublicKey) {
 			log.Printf("Discord signature verification failed")
 			return events.APIGatewayProxyResponse{
 				StatusCode: 401,
@@ -459,6 +5241,26 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		}, nil
 	}
 
+	if interaction.Type == InteractionTypeMessageComponent {
+		response := handleComponentInteraction(ctx, interaction)
+		responseBody, _ := json.Marshal(response)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       string(responseBody),
+		}, nil
+	}
+
+	if interaction.Type == InteractionTypeAutocomplete {
+		response := handleAutocompleteInteraction(ctx, interaction)
+		responseBody, _ := json.Marshal(response)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       string(responseBody),
+		}, nil
+	}
+
 	if interaction.Type != InteractionTypeApplicationCommand {
 		return events.APIGatewayProxyResponse{
 			StatusCode: 400,
@@ -466,13 +5268,54 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		}, nil
 	}
 
+	if os.Getenv("READONLY_MODE") == "true" && mutatingCommands[interaction.Data.Name] {
+		response := DiscordInteractionResponse{
+			Type: ResponseTypeChannelMessage,
+			Data: DiscordInteractionResponseData{
+				Content: ReadonlyModeNotice,
+				Flags:   MessageFlagEphemeral,
+			},
+		}
+		responseBody, _ := json.Marshal(response)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       string(responseBody),
+		}, nil
+	}
+
+	if expensiveCommands[interaction.Data.Name] {
+		if remaining := checkExpensiveCommandCooldown(ctx, interaction.ChannelID); remaining > 0 {
+			response := DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: fmt.Sprintf(CommandOnCooldown, int(remaining.Round(time.Second).Seconds())),
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+			responseBody, _ := json.Marshal(response)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       string(responseBody),
+			}, nil
+		}
+	}
+
 	var response DiscordInteractionResponse
 
 	switch interaction.Data.Name {
 	case "list":
-		response = handleListCommand(ctx, interaction.ChannelID)
+		rich, _ := boolOption(interaction.Data.Options, "rich")
+		status, _ := stringOption(interaction.Data.Options, "status")
+		page, ok := intOption(interaction.Data.Options, "page")
+		if !ok {
+			page = 1
+		}
+		response = handleListCommand(ctx, interaction.ChannelID, rich, status, page)
 	case "add":
-		if len(interaction.Data.Options) == 0 {
+		feedURL, ok := stringOption(interaction.Data.Options, "url")
+		if !ok {
 			response = DiscordInteractionResponse{
 				Type: ResponseTypeChannelMessage,
 				Data: DiscordInteractionResponseData{
@@ -481,11 +5324,14 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 				},
 			}
 		} else {
-			feedURL := interaction.Data.Options[0].Value.(string)
-			response = handleAddCommand(ctx, interaction.ChannelID, feedURL)
+			sendLatest, _ := boolOption(interaction.Data.Options, "latest")
+			response = handleAddPreviewCommand(ctx, interaction, feedURL, sendLatest)
 		}
+	case ContextCommandAddFeed:
+		response = handleAddFromMessageCommand(ctx, interaction)
 	case "remove":
-		if len(interaction.Data.Options) == 0 {
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
 			response = DiscordInteractionResponse{
 				Type: ResponseTypeChannelMessage,
 				Data: DiscordInteractionResponseData{
@@ -494,11 +5340,379 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 				},
 			}
 		} else {
-			feedIdentifier := interaction.Data.Options[0].Value.(string)
 			response = handleRemoveCommand(ctx, interaction.ChannelID, feedIdentifier)
 		}
+	case "pause", "resume":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else if interaction.Data.Name == "pause" {
+			response = handlePauseCommand(ctx, interaction.ChannelID, feedIdentifier)
+		} else {
+			response = handleResumeCommand(ctx, interaction.ChannelID, feedIdentifier)
+		}
+	case "note":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputNoteFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			note, _ := stringOption(interaction.Data.Options, "text")
+			response = handleNoteCommand(ctx, interaction.ChannelID, feedIdentifier, note)
+		}
+	case "block":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		keyword, hasKeyword := stringOption(interaction.Data.Options, "keyword")
+		if !ok || !hasKeyword {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputBlockKeyword,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleBlockCommand(ctx, interaction.ChannelID, feedIdentifier, keyword)
+		}
+	case "filter":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		keyword, hasKeyword := stringOption(interaction.Data.Options, "keyword")
+		if !ok || !hasKeyword {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputFilterKeyword,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleFilterCommand(ctx, interaction.ChannelID, feedIdentifier, keyword)
+		}
+	case "require-category":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		category, hasCategory := stringOption(interaction.Data.Options, "category")
+		if !ok || !hasCategory {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputRequireCategory,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleRequireCategoryCommand(ctx, interaction.ChannelID, feedIdentifier, category)
+		}
+	case "mirror":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		mirrorChannelID, hasMirrorChannelID := stringOption(interaction.Data.Options, "channel")
+		if !ok || !hasMirrorChannelID {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputMirror,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleMirrorCommand(ctx, interaction.ChannelID, feedIdentifier, mirrorChannelID)
+		}
+	case "feed-channel":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputFeedChannel,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			overrideChannelID, _ := stringOption(interaction.Data.Options, "channel")
+			response = handleFeedChannelCommand(ctx, interaction.ChannelID, feedIdentifier, overrideChannelID)
+		}
+	case "reorder":
+		from, ok := intOption(interaction.Data.Options, "from")
+		to, hasTo := intOption(interaction.Data.Options, "to")
+		if !ok || !hasTo {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputReorder,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleReorderCommand(ctx, interaction.ChannelID, from, to)
+		}
+	case "up", "down":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "feed")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputMoveFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			offset := -1
+			if interaction.Data.Name == "down" {
+				offset = 1
+			}
+			response = handleNudgeFeedCommand(ctx, interaction.ChannelID, feedIdentifier, offset)
+		}
+	case "delivery-mode":
+		mode, ok := stringOption(interaction.Data.Options, "mode")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputDeliveryMode,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleDeliveryModeCommand(ctx, interaction.ChannelID, mode)
+		}
+	case "thread-mode":
+		state, ok := stringOption(interaction.Data.Options, "state")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputOnOff,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleThreadModeCommand(ctx, interaction.ChannelID, state)
+		}
+	case "suppress-embeds":
+		state, ok := stringOption(interaction.Data.Options, "state")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputOnOff,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleSuppressEmbedsCommand(ctx, interaction.ChannelID, state)
+		}
+	case "update-notices":
+		state, ok := stringOption(interaction.Data.Options, "state")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputOnOff,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleUpdateNoticesCommand(ctx, interaction.ChannelID, state)
+		}
+	case "opt-out":
+		state, ok := stringOption(interaction.Data.Options, "state")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputOnOff,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleOptOutCommand(ctx, interaction.ChannelID, state)
+		}
+	case "post-interval":
+		seconds, ok := intOption(interaction.Data.Options, "seconds")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputPostInterval,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handlePostIntervalCommand(ctx, interaction.ChannelID, seconds)
+		}
+	case "burst-threshold":
+		threshold, ok := intOption(interaction.Data.Options, "count")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputBurstThreshold,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleBurstThresholdCommand(ctx, interaction.ChannelID, threshold)
+		}
+	case "language":
+		languageFilter, ok := stringOption(interaction.Data.Options, "filter")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputLanguageFilter,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleLanguageCommand(ctx, interaction.ChannelID, languageFilter)
+		}
+	case "fields":
+		input, ok := stringOption(interaction.Data.Options, "fields")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputDisplayFields,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleFieldsCommand(ctx, interaction.ChannelID, input)
+		}
+	case "user-agent":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputUserAgentFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			userAgent, _ := stringOption(interaction.Data.Options, "value")
+			response = handleUserAgentCommand(ctx, interaction.ChannelID, feedIdentifier, userAgent)
+		}
+	case "content":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		state, hasState := stringOption(interaction.Data.Options, "state")
+		if !ok || !hasState {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputContentFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleContentCommand(ctx, interaction.ChannelID, feedIdentifier, state)
+		}
+	case "min-age":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		minutes, hasMinutes := intOption(interaction.Data.Options, "minutes")
+		if !ok || !hasMinutes {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputMinItemAge,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleMinAgeCommand(ctx, interaction.ChannelID, feedIdentifier, minutes)
+		}
+	case "feed-hours":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "feed")
+		start, hasStart := intOption(interaction.Data.Options, "start")
+		end, hasEnd := intOption(interaction.Data.Options, "end")
+		if !ok || !hasStart || !hasEnd {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputFeedHours,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleFeedHoursCommand(ctx, interaction.ChannelID, feedIdentifier, start, end)
+		}
+	case "stats-feed":
+		feedIdentifier, ok := stringOption(interaction.Data.Options, "identifier")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputStatsFeed,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleStatsFeedCommand(ctx, interaction.ChannelID, feedIdentifier)
+		}
+	case "feed-info":
+		feedURL, ok := stringOption(interaction.Data.Options, "url")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputRssUrl,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleFeedInfoCommand(ctx, feedURL)
+		}
+	case "kill-switch":
+		state, ok := stringOption(interaction.Data.Options, "state")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputOnOff,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handleKillSwitchCommand(ctx, interactionUserID(interaction), state)
+		}
+	case "metrics-dump":
+		response = handleMetricsDumpCommand(ctx, interactionUserID(interaction))
+	case "stats":
+		response = handleStatsCommand(ctx, interaction.ChannelID)
+	case "rank":
+		response = handleRankCommand(ctx, interaction.ChannelID, interactionUserID(interaction))
+	case "prometheus":
+		response = handlePrometheusCommand(ctx, interactionUserID(interaction))
+	case "retry-failed":
+		response = handleRetryFailedCommand(ctx, interactionUserID(interaction))
+	case "repair":
+		response = handleRepairCommand(ctx, interactionUserID(interaction))
+	case "ping-post":
+		message, ok := stringOption(interaction.Data.Options, "message")
+		if !ok {
+			response = DiscordInteractionResponse{
+				Type: ResponseTypeChannelMessage,
+				Data: DiscordInteractionResponseData{
+					Content: ShouldInputPingPost,
+					Flags:   MessageFlagEphemeral,
+				},
+			}
+		} else {
+			response = handlePingPostCommand(ctx, interaction, message)
+		}
 	case "help":
-		response = handleHelpCommand()
+		topic, _ := stringOption(interaction.Data.Options, "command")
+		response = handleHelpCommand(topic)
 	default:
 		response = DiscordInteractionResponse{
 			Type: ResponseTypeChannelMessage,
@@ -525,5 +5739,13 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
+	if publicKey := os.Getenv("DISCORD_PUBLIC_KEY"); publicKey != "" {
+		pub, err := parseDiscordPublicKey(publicKey)
+		if err != nil {
+			log.Fatalf("DISCORD_PUBLIC_KEY is misconfigured: %v", err)
+		}
+		discordPublicKey = pub
+	}
+
 	lambda.Start(handleRequest)
 }
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeChannelCollection 은 채널 문서 하나에 $set, $push, $pull 과 rssUrl arrayFilter 를 적용하는 가짜 컬렉션이다.
+// 필터는 _id 와 $or, $exists, $lte 만 이해한다.
+type fakeChannelCollection struct {
+	mu      sync.Mutex
+	channel DiscordChannel
+	writes  int
+}
+
+func (c *fakeChannelCollection) FindOne(ctx context.Context, filter any, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.channel.ID == "" {
+		return mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+	}
+	return mongo.NewSingleResultFromDocument(c.channel, nil, nil)
+}
+
+func (c *fakeChannelCollection) InsertOne(ctx context.Context, document any, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writes++
+	c.channel = document.(DiscordChannel)
+	return &mongo.InsertOneResult{InsertedID: c.channel.ID}, nil
+}
+
+func (c *fakeChannelCollection) UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operations, ok := update.(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("unsupported update %T", update)
+	}
+	matched, err := c.matches(filter.(bson.M))
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return &mongo.UpdateResult{}, nil
+	}
+	c.writes++
+
+	var rssURL string
+	for _, opt := range opts {
+		if opt.ArrayFilters != nil {
+			rssURL = opt.ArrayFilters.Filters[0].(bson.M)["f.rssUrl"].(string)
+		}
+	}
+
+	for operator, fields := range operations {
+		fields := fields.(bson.M)
+		switch operator {
+		case "$set":
+			channelFields, feedFields := bson.M{}, bson.M{}
+			for key, value := range fields {
+				if field, ok := strings.CutPrefix(key, "feeds.$[f]."); ok {
+					feedFields[field] = value
+				} else {
+					channelFields[key] = value
+				}
+			}
+			if err := setDocumentFields(&c.channel, channelFields); err != nil {
+				return nil, err
+			}
+			for i := range c.channel.Feeds {
+				if len(feedFields) > 0 && c.channel.Feeds[i].RssURL == rssURL {
+					if err := setDocumentFields(&c.channel.Feeds[i], feedFields); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case "$push":
+			c.channel.Feeds = append(c.channel.Feeds, fields["feeds"].(Feed))
+		case "$pull":
+			pulled := fields["feeds"].(bson.M)["rssUrl"]
+			c.channel.Feeds = slices.DeleteFunc(c.channel.Feeds, func(feed Feed) bool { return feed.RssURL == pulled })
+		default:
+			return nil, fmt.Errorf("unsupported operator %s", operator)
+		}
+	}
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (c *fakeChannelCollection) matches(filter bson.M) (bool, error) {
+	data, err := bson.Marshal(c.channel)
+	if err != nil {
+		return false, err
+	}
+	document := bson.M{}
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return false, err
+	}
+
+	for key, condition := range filter {
+		switch {
+		case key == "$or":
+			matchedAny := false
+			for _, alternative := range condition.(bson.A) {
+				matched, err := c.matches(alternative.(bson.M))
+				if err != nil {
+					return false, err
+				}
+				matchedAny = matchedAny || matched
+			}
+			if !matchedAny {
+				return false, nil
+			}
+		case key == "_id":
+			if document["_id"] != condition {
+				return false, nil
+			}
+		default:
+			value, exists := document[key]
+			for operator, operand := range condition.(bson.M) {
+				switch operator {
+				case "$exists":
+					if exists != operand.(bool) {
+						return false, nil
+					}
+				case "$lte":
+					if !exists || value.(primitive.DateTime).Time().After(operand.(time.Time)) {
+						return false, nil
+					}
+				default:
+					return false, fmt.Errorf("unsupported filter operator %s", operator)
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+func setDocumentFields[T any](target *T, fields bson.M) error {
+	data, err := bson.Marshal(target)
+	if err != nil {
+		return err
+	}
+	document := bson.M{}
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		document[key] = value
+	}
+	if data, err = bson.Marshal(document); err != nil {
+		return err
+	}
+	var updated T
+	if err := bson.Unmarshal(data, &updated); err != nil {
+		return err
+	}
+	*target = updated
+	return nil
+}
+
+func TestSetFeedFieldsKeepsConcurrentProgress(t *testing.T) {
+	collection := &fakeChannelCollection{channel: DiscordChannel{
+		ID: "channel",
+		Feeds: []Feed{
+			{BlogName: "A", RssURL: "https://a.example.com/rss", LastPostLink: "https://a.example.com/1"},
+			{BlogName: "B", RssURL: "https://b.example.com/rss", LastPostLink: "https://b.example.com/1"},
+		},
+	}}
+
+	// 명령어가 채널을 읽은 뒤 RSS Lambda 가 중복 확인 기준을 옮겼다
+	loaded := collection.channel
+	collection.channel.Feeds = []Feed{
+		{BlogName: "A", RssURL: "https://a.example.com/rss", LastPostLink: "https://a.example.com/2"},
+		{BlogName: "B", RssURL: "https://b.example.com/rss", LastPostLink: "https://b.example.com/2"},
+	}
+
+	if err := setFeedFields(context.Background(), collection, loaded.ID, loaded.Feeds[0].RssURL, bson.M{"paused": true}); err != nil {
+		t.Fatalf("setFeedFields() error = %v", err)
+	}
+
+	feeds := collection.channel.Feeds
+	if !feeds[0].Paused || feeds[1].Paused {
+		t.Errorf("paused = %v, %v; want only A paused", feeds[0].Paused, feeds[1].Paused)
+	}
+	if feeds[0].LastPostLink != "https://a.example.com/2" || feeds[1].LastPostLink != "https://b.example.com/2" {
+		t.Errorf("last post links = %q, %q; progress saved in between was overwritten", feeds[0].LastPostLink, feeds[1].LastPostLink)
+	}
+}
+
+func TestConcurrentCommandsDoNotOverwriteEachOther(t *testing.T) {
+	collection := &fakeChannelCollection{channel: DiscordChannel{ID: "channel"}}
+	for i := range 10 {
+		collection.channel.Feeds = append(collection.channel.Feeds, Feed{BlogName: fmt.Sprintf("blog-%d", i), RssURL: fmt.Sprintf("https://example.com/%d/rss", i)})
+	}
+	feeds := collection.channel.Feeds
+
+	var wg sync.WaitGroup
+	for _, feed := range feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := setFeedFields(context.Background(), collection, "channel", feed.RssURL, bson.M{"note": feed.BlogName}); err != nil {
+				t.Errorf("setFeedFields() error = %v", err)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := setChannelFields(context.Background(), collection, "channel", bson.M{"deliveryMode": DeliveryModeSummary}); err != nil {
+			t.Errorf("setChannelFields() error = %v", err)
+		}
+	}()
+	wg.Wait()
+
+	for _, feed := range collection.channel.Feeds {
+		if feed.Note != feed.BlogName {
+			t.Errorf("feed %s note = %q, want %q", feed.BlogName, feed.Note, feed.BlogName)
+		}
+	}
+	if collection.channel.DeliveryMode != DeliveryModeSummary {
+		t.Errorf("deliveryMode = %q, want %q", collection.channel.DeliveryMode, DeliveryModeSummary)
+	}
+}
+
+func TestSetFeedFieldsRacesWithAddAndRemove(t *testing.T) {
+	for run := range 20 {
+		collection := &fakeChannelCollection{channel: DiscordChannel{
+			ID: "channel",
+			Feeds: []Feed{
+				{BlogName: "A", RssURL: "https://a.example.com/rss"},
+				{BlogName: "B", RssURL: "https://b.example.com/rss"},
+			},
+		}}
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := pushFeed(context.Background(), collection, "channel", Feed{BlogName: "C", RssURL: "https://c.example.com/rss"}); err != nil {
+				t.Errorf("pushFeed() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := pullFeed(context.Background(), collection, "channel", "https://b.example.com/rss"); err != nil {
+				t.Errorf("pullFeed() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := setFeedFields(context.Background(), collection, "channel", "https://a.example.com/rss", bson.M{"paused": true}); err != nil {
+				t.Errorf("setFeedFields() error = %v", err)
+			}
+		}()
+		wg.Wait()
+
+		feeds := collection.channel.Feeds
+		if len(feeds) != 2 || feeds[0].RssURL != "https://a.example.com/rss" || feeds[1].RssURL != "https://c.example.com/rss" {
+			t.Fatalf("run %d: feeds = %+v, want A and the added C", run, feeds)
+		}
+		if !feeds[0].Paused {
+			t.Errorf("run %d: A is not paused", run)
+		}
+	}
+}
+
+func TestComputeChannelStats(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		feeds []Feed
+		want  ChannelStats
+	}{
+		{
+			name:  "empty channel",
+			feeds: nil,
+			want:  ChannelStats{MostActive: -1, Oldest: -1, Newest: -1},
+		},
+		{
+			name: "totals and most active",
+			feeds: []Feed{
+				{BlogName: "A", TotalPostsSent: 3, AddedAt: day(2)},
+				{BlogName: "B", TotalPostsSent: 7, AddedAt: day(1)},
+				{BlogName: "C", TotalPostsSent: 1, AddedAt: day(3)},
+			},
+			want: ChannelStats{TotalFeeds: 3, TotalPostsSent: 11, MostActive: 1, Oldest: 1, Newest: 2},
+		},
+		{
+			name: "tie keeps the earlier feed",
+			feeds: []Feed{
+				{BlogName: "A", TotalPostsSent: 5, AddedAt: day(1)},
+				{BlogName: "B", TotalPostsSent: 5, AddedAt: day(2)},
+			},
+			want: ChannelStats{TotalFeeds: 2, TotalPostsSent: 10, MostActive: 0, Oldest: 0, Newest: 1},
+		},
+		{
+			name: "no posts sent",
+			feeds: []Feed{
+				{BlogName: "A", AddedAt: day(1)},
+			},
+			want: ChannelStats{TotalFeeds: 1, MostActive: -1, Oldest: 0, Newest: 0},
+		},
+		{
+			name: "zero added at is left out of oldest and newest",
+			feeds: []Feed{
+				{BlogName: "legacy", TotalPostsSent: 2},
+				{BlogName: "A", AddedAt: day(5)},
+				{BlogName: "B", AddedAt: day(3)},
+			},
+			want: ChannelStats{TotalFeeds: 3, TotalPostsSent: 2, MostActive: 0, Oldest: 2, Newest: 1},
+		},
+		{
+			name: "only zero added at",
+			feeds: []Feed{
+				{BlogName: "legacy"},
+			},
+			want: ChannelStats{TotalFeeds: 1, MostActive: -1, Oldest: -1, Newest: -1},
+		},
+		{
+			name: "paused feeds",
+			feeds: []Feed{
+				{BlogName: "A", Paused: true, AddedAt: day(1)},
+				{BlogName: "B", AddedAt: day(2)},
+				{BlogName: "C", Paused: true, AddedAt: day(3)},
+			},
+			want: ChannelStats{TotalFeeds: 3, MostActive: -1, Oldest: 0, Newest: 2, PausedFeeds: []string{"A", "C"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeChannelStats(DiscordChannel{Feeds: tt.feeds})
+			if got.TotalFeeds != tt.want.TotalFeeds || got.TotalPostsSent != tt.want.TotalPostsSent ||
+				got.MostActive != tt.want.MostActive || got.Oldest != tt.want.Oldest || got.Newest != tt.want.Newest ||
+				!slices.Equal(got.PausedFeeds, tt.want.PausedFeeds) {
+				t.Errorf("computeChannelStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddFeedRefusalMaxFeedsPerChannel(t *testing.T) {
+	t.Setenv("MAX_FEEDS_PER_CHANNEL", "2")
+
+	var feeds []Feed
+	for i := range 3 {
+		feedURL := fmt.Sprintf("https://example.com/%d/rss", i)
+		refusal := addFeedRefusal(feeds, feedURL)
+		if i < 2 {
+			if refusal != "" {
+				t.Fatalf("add #%d refused: %q", i+1, refusal)
+			}
+			feeds = append(feeds, Feed{RssURL: feedURL})
+			continue
+		}
+		if want := fmt.Sprintf(TooManyFeedsInChannel, 2); refusal != want {
+			t.Errorf("add #%d refusal = %q, want %q", i+1, refusal, want)
+		}
+	}
+}
+
+func TestFeedAutocompleteChoices(t *testing.T) {
+	var feeds []Feed
+	for i := range 30 {
+		feeds = append(feeds, Feed{BlogName: fmt.Sprintf("Tech Blog %d", i+1), RssURL: fmt.Sprintf("https://blog%d.example.com/rss", i+1)})
+	}
+	feeds = append(feeds, Feed{BlogName: "카카오 기술 블로그", RssURL: "https://tech.kakao.com/feed/"})
+
+	t.Run("partial query", func(t *testing.T) {
+		choices := feedAutocompleteChoices(feeds, "카카오")
+		if len(choices) != 1 {
+			t.Fatalf("got %d choices, want 1: %+v", len(choices), choices)
+		}
+		if want := (DiscordAutocompleteChoice{Name: "31. 카카오 기술 블로그", Value: "31"}); choices[0] != want {
+			t.Errorf("choice = %+v, want %+v", choices[0], want)
+		}
+	})
+
+	t.Run("empty query is capped", func(t *testing.T) {
+		choices := feedAutocompleteChoices(feeds, "")
+		if len(choices) != MaxAutocompleteChoices {
+			t.Fatalf("got %d choices, want %d", len(choices), MaxAutocompleteChoices)
+		}
+		if choices[0].Value != "1" {
+			t.Errorf("first choice value = %q, want %q", choices[0].Value, "1")
+		}
+	})
+
+	t.Run("matching query is capped", func(t *testing.T) {
+		if choices := feedAutocompleteChoices(feeds, "tech blog"); len(choices) != MaxAutocompleteChoices {
+			t.Errorf("got %d choices, want %d", len(choices), MaxAutocompleteChoices)
+		}
+	})
+}
+
+func TestAddFeedRefusalTrailingSlash(t *testing.T) {
+	feeds := []Feed{{BlogName: "NAVER D2", RssURL: "https://d2.naver.com/d2.atom"}}
+
+	refusal := addFeedRefusal(feeds, "https://d2.naver.com/d2.atom/")
+	if want := fmt.Sprintf("%s: **%s**", AlreadyRegisteredFeed, "NAVER D2"); refusal != want {
+		t.Errorf("addFeedRefusal() = %q, want %q", refusal, want)
+	}
+}
+
+func TestRepairedLastPostLink(t *testing.T) {
+	parsed := &gofeed.Feed{Items: []*gofeed.Item{
+		{Title: "새 글", Link: "https://example.com/3"},
+		{Title: "Kafka 튜닝", Link: "https://example.com/2"},
+		{Title: "첫 글", Link: "https://example.com/1"},
+	}}
+
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"matches the legacy title", "kafka 튜닝", "https://example.com/2"},
+		{"falls back to the newest item", "사라진 글", "https://example.com/3"},
+		{"no legacy title", "", "https://example.com/3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repairedLastPostLink(Feed{LastPostTitle: tt.title}, parsed); got != tt.want {
+				t.Errorf("repairedLastPostLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := repairedLastPostLink(Feed{}, &gofeed.Feed{}); got != "" {
+		t.Errorf("repairedLastPostLink() on an empty feed = %q, want empty", got)
+	}
+}
+
+func TestBuildFeedStatsContent(t *testing.T) {
+	feed := Feed{
+		BlogName:            "냥블로그",
+		RssURL:              "https://example.com/feed",
+		TotalPostsSent:      12,
+		ConsecutiveFailures: 1,
+		AvgParseMs:          1500,
+		AvgDeliverySeconds:  540,
+	}
+
+	content := buildFeedStatsContent(DiscordChannel{}, feed)
+	for _, want := range []string{
+		"📊 **냥블로그 피드 통계다냥~**",
+		"📎 https://example.com/feed",
+		"📨 전송된 포스트: 12개",
+		"⚠️ 연속 실패 횟수: 1회",
+		"⏱️ 평균 조회 시간: 1.5초",
+		"🚚 평균 전송 지연: 9m0s",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("stats content is missing %q:\n%s", want, content)
+		}
+	}
+
+	feed.AvgDeliverySeconds = 0
+	if content := buildFeedStatsContent(DiscordChannel{}, feed); strings.Contains(content, "평균 전송 지연") {
+		t.Errorf("stats content shows delivery latency without a measurement:\n%s", content)
+	}
+}
+
+func TestClaimExpensiveCommandOnlyOneWins(t *testing.T) {
+	collection := &fakeChannelCollection{channel: DiscordChannel{ID: "channel"}}
+	now := time.Now().Truncate(time.Millisecond)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claims := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claimed, err := claimExpensiveCommand(context.Background(), collection, "channel", 10*time.Second, now)
+			if err != nil {
+				t.Errorf("claimExpensiveCommand() error = %v", err)
+				return
+			}
+			if claimed {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claims != 1 {
+		t.Fatalf("%d concurrent commands got past the cooldown, want 1", claims)
+	}
+	if claimed, _ := claimExpensiveCommand(context.Background(), collection, "channel", 10*time.Second, now.Add(5*time.Second)); claimed {
+		t.Errorf("claimExpensiveCommand() succeeded while still cooling down")
+	}
+	if claimed, _ := claimExpensiveCommand(context.Background(), collection, "channel", 10*time.Second, now.Add(10*time.Second)); !claimed {
+		t.Errorf("claimExpensiveCommand() refused after the cooldown passed")
+	}
+}
+
+func TestAddFeedToChannelRefusesBeforeFetching(t *testing.T) {
+	fetches := 0
+	originalValidateFeed := validateFeed
+	validateFeed = func(ctx context.Context, url string) (*gofeed.Feed, error) {
+		fetches++
+		return &gofeed.Feed{Title: "New Blog"}, nil
+	}
+	t.Cleanup(func() { validateFeed = originalValidateFeed })
+
+	t.Setenv("MAX_FEEDS_PER_CHANNEL", "1")
+	interaction := DiscordInteraction{ChannelID: "channel"}
+
+	for name, feedURL := range map[string]string{
+		"duplicate": "https://d2.naver.com/d2.atom/",
+		"limit":     "https://example.com/feed",
+	} {
+		t.Run(name, func(t *testing.T) {
+			fetches = 0
+			collection := &fakeChannelCollection{channel: DiscordChannel{
+				ID:    "channel",
+				Feeds: []Feed{{BlogName: "NAVER D2", RssURL: "https://d2.naver.com/d2.atom"}},
+			}}
+
+			refusal := addFeedRefusal(collection.channel.Feeds, feedURL)
+			if refusal == "" {
+				t.Fatalf("addFeedRefusal() did not refuse %s", feedURL)
+			}
+			response := addFeedToChannel(context.Background(), collection, interaction, feedURL, false)
+			if response.Data.Content != refusal {
+				t.Errorf("response = %q, want %q", response.Data.Content, refusal)
+			}
+			if fetches != 0 {
+				t.Errorf("fetched the feed %d times before refusing", fetches)
+			}
+			if collection.writes != 0 {
+				t.Errorf("wrote to the channel %d times before refusing", collection.writes)
+			}
+		})
+	}
+
+	t.Run("new channel", func(t *testing.T) {
+		fetches = 0
+		collection := &fakeChannelCollection{}
+
+		addFeedToChannel(context.Background(), collection, interaction, "https://example.com/feed", false)
+		if fetches != 1 || collection.writes != 1 {
+			t.Errorf("fetches = %d, writes = %d, want 1 and 1", fetches, collection.writes)
+		}
+		if len(collection.channel.Feeds) != 1 || collection.channel.Feeds[0].BlogName != "New Blog" {
+			t.Errorf("channel feeds = %+v, want the new feed", collection.channel.Feeds)
+		}
+	})
+}
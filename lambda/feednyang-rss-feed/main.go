@@ -1,38 +1,136 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
 	"log"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/bwmarrin/discordgo"
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 type Feed struct {
-	BlogName       string    `bson:"blogName" json:"blogName"`
-	RssURL         string    `bson:"rssUrl" json:"rssUrl"`
-	AddedAt        time.Time `bson:"addedAt" json:"addedAt"`
-	LastSentTime   time.Time `bson:"lastSentTime" json:"lastSentTime"`
-	LastPostLink   string    `bson:"lastPostLink" json:"lastPostLink"`
-	TotalPostsSent int       `bson:"totalPostsSent" json:"totalPostsSent"`
+	BlogName            string    `bson:"blogName" json:"blogName"`
+	RssURL              string    `bson:"rssUrl" json:"rssUrl"`
+	AddedAt             time.Time `bson:"addedAt" json:"addedAt"`
+	LastSentTime        time.Time `bson:"lastSentTime" json:"lastSentTime"`
+	LastPostLink        string    `bson:"lastPostLink" json:"lastPostLink"`
+	TotalPostsSent      int       `bson:"totalPostsSent" json:"totalPostsSent"`
+	Note                string    `bson:"note,omitempty" json:"note,omitempty"`
+	ConsecutiveFailures int       `bson:"consecutiveFailures" json:"consecutiveFailures"`
+	BlockKeywords       []string  `bson:"blockKeywords,omitempty" json:"blockKeywords,omitempty"`
+	UserAgent           string    `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	ThreadID            string    `bson:"threadId,omitempty" json:"threadId,omitempty"`
+	LastError           string    `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	MirrorChannelIDs    []string  `bson:"mirrorChannelIds,omitempty" json:"mirrorChannelIds,omitempty"`
+	SiteURL             string    `bson:"siteUrl,omitempty" json:"siteUrl,omitempty"`
+	IconURL             string    `bson:"iconUrl,omitempty" json:"iconUrl,omitempty"`
+	ShortID             string    `bson:"shortId,omitempty" json:"shortId,omitempty"`
+	PollInterval        int       `bson:"pollInterval,omitempty" json:"pollInterval,omitempty"`
+	LastCheckedAt       time.Time `bson:"lastCheckedAt,omitempty" json:"lastCheckedAt,omitempty"`
+	ActiveHoursStart    int       `bson:"activeHoursStart,omitempty" json:"activeHoursStart,omitempty"`
+	ActiveHoursEnd      int       `bson:"activeHoursEnd,omitempty" json:"activeHoursEnd,omitempty"`
+	AvgParseMs          int64     `bson:"avgParseMs,omitempty" json:"avgParseMs,omitempty"`
+	AvgDeliverySeconds  int64     `bson:"avgDeliverySeconds,omitempty" json:"avgDeliverySeconds,omitempty"`
+	LastPostUpdatedAt   time.Time `bson:"lastPostUpdatedAt,omitempty" json:"lastPostUpdatedAt,omitempty"`
+	OverrideChannelID   string    `bson:"overrideChannelId,omitempty" json:"overrideChannelId,omitempty"`
+	IntroSent           bool      `bson:"introSent,omitempty" json:"introSent,omitempty"`
+	IncludeContent      bool      `bson:"includeContent,omitempty" json:"includeContent,omitempty"`
+	AddedBy             string    `bson:"addedBy,omitempty" json:"addedBy,omitempty"`
+	MinItemAge          int       `bson:"minItemAge,omitempty" json:"minItemAge,omitempty"`
+	RequireCategories   []string  `bson:"requireCategories,omitempty" json:"requireCategories,omitempty"`
+	Keywords            []string  `bson:"keywords,omitempty" json:"keywords,omitempty"`
+	Paused              bool      `bson:"paused,omitempty" json:"paused,omitempty"`
 }
 
 type DiscordChannel struct {
-	ID        string    `bson:"_id" json:"_id"`
-	Feeds     []Feed    `bson:"feeds" json:"feeds"`
-	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+	ID                string    `bson:"_id" json:"_id"`
+	Feeds             []Feed    `bson:"feeds" json:"feeds"`
+	DeliveryMode      string    `bson:"deliveryMode,omitempty" json:"deliveryMode,omitempty"`
+	ThreadMode        bool      `bson:"threadMode,omitempty" json:"threadMode,omitempty"`
+	DisplayFields     []string  `bson:"displayFields,omitempty" json:"displayFields,omitempty"`
+	MinPostInterval   int       `bson:"minPostInterval,omitempty" json:"minPostInterval,omitempty"`
+	BurstThreshold    int       `bson:"burstThreshold,omitempty" json:"burstThreshold,omitempty"`
+	LanguageFilter    string    `bson:"languageFilter,omitempty" json:"languageFilter,omitempty"`
+	SuppressEmbeds    bool      `bson:"suppressEmbeds,omitempty" json:"suppressEmbeds,omitempty"`
+	UpdateNotices     bool      `bson:"updateNotices,omitempty" json:"updateNotices,omitempty"`
+	FollowsDefaults   bool      `bson:"followsDefaults,omitempty" json:"followsDefaults,omitempty"`
+	LastChannelPostAt time.Time `bson:"lastChannelPostAt,omitempty" json:"lastChannelPostAt,omitempty"`
+	LastExpensiveOpAt time.Time `bson:"lastExpensiveOpAt,omitempty" json:"lastExpensiveOpAt,omitempty"`
+	CreatedBy         string    `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt         time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// 재시도 끝에 보내지 못해 /retry-failed 를 기다리는 글이다.
+type FailedSend struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ChannelID      string             `bson:"channelId" json:"channelId"`
+	BlogName       string             `bson:"blogName" json:"blogName"`
+	RssURL         string             `bson:"rssUrl" json:"rssUrl"`
+	ItemTitle      string             `bson:"itemTitle" json:"itemTitle"`
+	ItemLink       string             `bson:"itemLink" json:"itemLink"`
+	Content        string             `bson:"content" json:"content"`
+	SuppressEmbeds bool               `bson:"suppressEmbeds,omitempty" json:"suppressEmbeds,omitempty"`
+	Error          string             `bson:"error" json:"error"`
+	FailedAt       time.Time          `bson:"failedAt" json:"failedAt"`
+}
+
+type BotConfig struct {
+	ID                string    `bson:"_id" json:"_id"`
+	Paused            bool      `bson:"paused" json:"paused"`
+	LastErrorReportAt time.Time `bson:"lastErrorReportAt,omitempty" json:"lastErrorReportAt,omitempty"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type ImportAllDetail struct {
+	Key     string `json:"key"`
+	Mode    string `json:"mode"`
+	Confirm string `json:"confirm"`
+}
+
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
 }
 
 type LambdaEvent struct {
@@ -46,11 +144,99 @@ type LambdaResponse struct {
 	Body       string `json:"body"`
 }
 
+// 채널 고루틴들이 나눠 쓰는 한 번의 실행의 전송 한도다.
+type sendBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+func (b *sendBudget) take(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	taken := min(n, b.remaining)
+	b.remaining -= taken
+	return taken
+}
+
+// 채널 안에서 여러 곳으로 동시에 보내도 전체 처리량이 Discord 전역 한도를 넘지 않게 한다.
+type requestRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *requestRateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+type mirrorSend struct {
+	feedConfig Feed
+	item       *gofeed.Item
+	embed      *discordgo.MessageEmbed
+	content    string
+}
+
 type channelProcessResult struct {
-	channel     DiscordChannel
-	newItems    int
-	needsUpdate bool
-	err         error
+	// original 은 처리하기 전의 채널이다. 처리한 채널과 비교해서 바뀐 필드만 저장한다
+	original     DiscordChannel
+	channel      DiscordChannel
+	newItems     int
+	needsUpdate  bool
+	failedSends  []FailedSend
+	feedFailures []feedFailure
+	err          error
+}
+
+type feedFailure struct {
+	rssURL   string
+	blogName string
+	reason   string
+}
+
+// 요약 모드에서는 한 피드의 새 글 여러 개가 하나로 묶인다.
+type pendingPost struct {
+	feedIndex int
+	items     []*gofeed.Item
+	// 밀린 글이 너무 많아 최신 글 몇 개만 보여줄 때 원래 새 글 개수다. 0 이면 일반 전송이다
+	burstTotal int
+}
+
+type feedFetchResult struct {
+	feed *gofeed.Feed
+	err  error
+	// 마지막 시도에서 피드를 가져와 파싱하는 데 걸린 시간 (재시도 대기 시간은 빠진다)
+	elapsed time.Duration
+	// 실제로 시도한 횟수. 로그인이 필요한 피드는 한 번만 시도한다
+	attempts int
+	// 피드가 밝힌 폴링 간격이 아직 지나지 않아 이번 실행에서는 가져오지 않았다
+	skipped bool
+}
+
+// 네트워크 장애로 피드가 한꺼번에 실패해도 로그가 피드 수만큼 불어나지 않도록 원인별로 모은다.
+type failureLogAggregator struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	samples map[string][]string
+	order   []string
 }
 
 type feedParseResult struct {
@@ -58,6 +244,130 @@ type feedParseResult struct {
 	err  error
 }
 
+const (
+	MaxFeedBodySize   = 10 << 20
+	MinPollInterval   = 15
+	MaxPollInterval   = 24 * 60
+	PollIntervalSlack = 5 * time.Minute
+	// 피드를 가져오는 데 걸린 평균 시간이 이보다 길면 느린 피드로 보고 경고를 남긴다
+	SlowFeedThreshold = 10 * time.Second
+	// 평균 파싱 시간은 새 값을 1/ParseLatencySmoothing 만큼만 반영하는 이동 평균이다
+	ParseLatencySmoothing = 4
+	// 조금씩 흔들리는 평균 파싱 시간 때문에 매번 채널 문서를 쓰지 않도록 이 단위로 반올림한다
+	ParseLatencyResolution = 100 * time.Millisecond
+	FailedSendRetention    = 7 * 24 * time.Hour
+	MetricNamespace        = "FeedNyang"
+	// 장애가 길어져도 운영자 채널에 리포트가 쌓이지 않도록 이 간격에 한 번만 보낸다
+	OperatorReportInterval = 6 * time.Hour
+
+	DetailTypeSyncDefaultFeeds = "sync-default-feeds"
+	DetailTypeExportAll        = "export-all"
+	DetailTypeImportAll        = "import-all"
+	ExportKeyPrefix            = "exports"
+	ImportModeMerge            = "merge"
+	ImportModeReplace          = "replace"
+	// replace 모드는 지금 데이터를 덮어쓰므로 detail.confirm 에 이 값을 적어야 실행한다
+	ImportReplaceConfirmation = "overwrite-live-data"
+	// MongoDB 문서 최대 크기 (16MB) 와 같게 잡아서 한 줄에 채널 하나가 다 들어가도록 한다
+	MaxImportLineSize   = 16 << 20
+	MongoRetryAttempts  = 3
+	MongoRetryBaseDelay = 200 * time.Millisecond
+
+	DefaultUserAgent = "Mozilla/5.0 (compatible; FeedNyang/1.0; +https://github.com/nmin11/feednyang)"
+
+	SendOrderInsertion  = "insertion"
+	SendOrderAlpha      = "alpha"
+	SendOrderRoundRobin = "round-robin"
+
+	DeliveryModeItem    = "item"
+	DeliveryModeSummary = "summary"
+
+	DiscordMessageLimit = 2000
+	// 임베드 제목과 설명의 Discord 글자 수 제한
+	EmbedTitleLimit       = 256
+	EmbedDescriptionLimit = 4096
+
+	ThreadNameLimit           = 100
+	DefaultGraceWindow        = 10 * time.Minute
+	DefaultCatchupWindow      = 48 * time.Hour
+	DefaultMaxSendsPerRun     = 200
+	DefaultFeedRetryAttempts  = 3
+	DefaultFeedRetryBaseDelay = 2 * time.Second
+	DefaultSendConcurrency    = 1
+	// 기준 글을 찾지 못한 채 새 글이 이보다 많으면 피드 형식이 바뀐 것으로 본다
+	DefaultFormatChangeThreshold = 10
+	// Discord 전역 한도(초당 50회)보다 조금 여유를 둔 요청 간격
+	DiscordRequestInterval    = time.Second / 40
+	FeedWorkersPerCPU         = 8
+	ChannelWorkersPerCPU      = 3
+	MaxChannelPostWait        = time.Minute
+	ChannelPostDeadlineMargin = 30 * time.Second
+	MaxDescriptionLength      = 200
+	BurstPreviewCount         = 5
+	MaxBurstThreshold         = 100
+	DisplayFieldDate          = "date"
+	DisplayFieldAuthor        = "author"
+	DisplayFieldDescription   = "description"
+	DisplayFieldLink          = "link"
+	ThreadAutoArchiveDuration = 10080
+
+	LanguageFilterKorean    = "ko"
+	LanguageFilterNonKorean = "en"
+
+	FeedErrorFetchFailed   = "fetch-failed"
+	FeedErrorLoginRequired = "login-required"
+)
+
+var (
+	errGlobalPaused      = errors.New("posting is globally paused")
+	errMongoUnavailable  = errors.New("MongoDB is unavailable")
+	errFeedLoginRequired = errors.New("feed requires login")
+)
+
+var (
+	botTokenMu     sync.Mutex
+	cachedBotToken string
+	// discordSession 은 모든 전송이 discordgo 의 레이트 리밋 상태를 같이 쓰도록 실행 사이에도 재사용한다
+	discordSessionMu    sync.Mutex
+	discordSession      discordSender
+	discordSessionToken string
+	// 테스트에서는 가짜 세션을 만들도록 바꾼다
+	newDiscordSession = func(botToken string) (discordSender, error) {
+		return discordgo.New("Bot " + botToken)
+	}
+
+	mongoURIMu     sync.Mutex
+	cachedMongoURI string
+	// 웜 컨테이너의 다음 호출이 연결 풀을 그대로 쓰도록 닫지 않는다
+	mongoClientMu     sync.Mutex
+	cachedMongoClient *mongo.Client
+	// 캐시된 클라이언트로 한 작업이 실패했으면 다음 연결 때 한 번 Ping 해 본다
+	mongoClientSuspect bool
+	// 테스트에서는 서버 없이 통과하도록 바꾼다
+	pingMongoDB = func(ctx context.Context, client *mongo.Client) error {
+		return client.Ping(ctx, nil)
+	}
+
+	utf8BOM   = []byte{0xEF, 0xBB, 0xBF}
+	gzipMagic = []byte{0x1f, 0x8b}
+	// 피드 본문 맨 앞의 XML 선언에서 encoding 값을 찾는다
+	xmlEncodingPattern = regexp.MustCompile(`<\?xml[^>]*?encoding\s*=\s*["']([^"']+)["']`)
+
+	failureLog = &failureLogAggregator{}
+
+	discordRateLimiter = &requestRateLimiter{interval: DiscordRequestInterval}
+
+	// 피드 재시도 설정은 main 에서 환경 변수로 한 번만 읽는다
+	feedRetryAttempts  = DefaultFeedRetryAttempts
+	feedRetryBaseDelay = DefaultFeedRetryBaseDelay
+
+	kst            = time.FixedZone("KST", 9*60*60)
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+	// CDATA 안에 한 번 더 이스케이프된 HTML 태그나 엔티티 (예: &lt;p&gt;, &amp;nbsp;)
+	escapedHTMLPattern   = regexp.MustCompile(`&lt;/?[a-zA-Z][^<>]*?&gt;|&amp;(?:[a-zA-Z]+|#[0-9]+|#x[0-9a-fA-F]+);`)
+	defaultDisplayFields = []string{DisplayFieldLink}
+)
+
 // 기본 RSS 피드 목록
 var techBlogFeeds = []struct {
 	Name string
@@ -85,293 +395,2801 @@ var techBlogFeeds = []struct {
 	{"Pinterest Engineering", "https://medium.com/feed/@Pinterest_Engineering"},
 }
 
-func connectMongoDB(ctx context.Context) (*mongo.Client, error) {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		return nil, fmt.Errorf("MONGODB_URI environment variable not set")
+func getMongoURI(ctx context.Context) (string, error) {
+	secretARN := os.Getenv("MONGODB_URI_SECRET_ARN")
+	if secretARN == "" {
+		mongoURI := os.Getenv("MONGODB_URI")
+		if mongoURI == "" {
+			return "", fmt.Errorf("MONGODB_URI environment variable not set")
+		}
+		return mongoURI, nil
 	}
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	mongoURIMu.Lock()
+	defer mongoURIMu.Unlock()
+
+	if cachedMongoURI != "" {
+		return cachedMongoURI, nil
 	}
 
-	err = client.Ping(ctx, nil)
+	mongoURI, err := fetchSecretString(ctx, secretARN)
 	if err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %v", err)
+		return "", fmt.Errorf("failed to fetch MongoDB URI from Secrets Manager: %v", err)
 	}
 
-	return client, nil
+	cachedMongoURI = mongoURI
+	return cachedMongoURI, nil
 }
 
-func sendDiscordMessage(channelID string, content string) error {
-	botToken := os.Getenv("DISCORD_BOT_TOKEN")
-	if botToken == "" {
-		return fmt.Errorf("DISCORD_BOT_TOKEN environment variable not set")
+func isTransientMongoError(err error) bool {
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, context.Canceled) {
+		return false
 	}
 
-	session, err := discordgo.New("Bot " + botToken)
-	if err != nil {
-		return fmt.Errorf("failed to create Discord session: %v", err)
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
 	}
 
-	_, err = session.ChannelMessageSend(channelID, content)
-	if err != nil {
-		return fmt.Errorf("failed to send Discord message: %v", err)
+	var labeledErr mongo.LabeledError
+	if errors.As(err, &labeledErr) {
+		return labeledErr.HasErrorLabel("TransientTransactionError") || labeledErr.HasErrorLabel("RetryableWriteError")
 	}
 
-	return nil
+	return false
 }
 
-func ensureDefaultChannels(ctx context.Context, channelCollection *mongo.Collection, fp *gofeed.Parser) error {
-	defaultChannelIDs := os.Getenv("DEFAULT_DISCORD_CHANNEL_IDS")
-	if defaultChannelIDs == "" {
-		log.Println("No default channel IDs provided, skipping initialization")
-		return nil
-	}
-
-	channelIDs := strings.SplitSeq(defaultChannelIDs, ",")
-
-	for channelID := range channelIDs {
-		channelID = strings.TrimSpace(channelID)
-		if channelID == "" {
-			continue
-		}
-
-		count, err := channelCollection.CountDocuments(ctx, bson.M{"_id": channelID})
-		if err != nil {
-			log.Printf("Error checking channel %s: %v", channelID, err)
-			continue
+// 일시적인 MongoDB 오류만 재시도하고 ErrNoDocuments 같은 오류는 그대로 돌려준다.
+func withMongoRetry(ctx context.Context, operation func() error) error {
+	var err error
+	for attempt := range MongoRetryAttempts {
+		err = operation()
+		if !isTransientMongoError(err) {
+			return err
 		}
 
-		if count > 0 {
-			continue
+		if attempt < MongoRetryAttempts-1 {
+			waitTime := MongoRetryBaseDelay * time.Duration(1<<attempt)
+			log.Printf("Transient MongoDB error (attempt %d/%d): %v. Retrying in %v", attempt+1, MongoRetryAttempts, err, waitTime)
+			select {
+			case <-ctx.Done():
+				markMongoClientSuspect()
+				return err
+			case <-time.After(waitTime):
+			}
 		}
+	}
+	markMongoClientSuspect()
+	return err
+}
 
-		channel := DiscordChannel{
-			ID:        channelID,
-			Feeds:     []Feed{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
+type channelUpdater interface {
+	UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+}
 
-		var feedWg sync.WaitGroup
-		feedResults := make(chan feedParseResult, len(techBlogFeeds))
+// 문서를 통째로 덮어쓰지 않아야 그 사이 다른 곳에서 바꾼 설정을 지우지 않는다.
+func pushFeeds(ctx context.Context, channelCollection channelUpdater, channelID string, feeds []Feed) error {
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx,
+			bson.M{"_id": channelID},
+			bson.M{
+				"$push": bson.M{"feeds": bson.M{"$each": feeds}},
+				"$set":  bson.M{"updatedAt": time.Now()},
+			},
+		)
+		return err
+	})
+}
 
-		for _, feedInfo := range techBlogFeeds {
-			feedWg.Add(1)
-			go func(info struct{ Name, URL string }) {
-				defer feedWg.Done()
+// omitempty 필드는 비어 있으면 빠진다.
+func documentFields(value any) (bson.M, error) {
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
 
-				now := time.Now()
-				var lastPostLink string
-				var lastSentTime time.Time = now
+	var fields bson.M
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
 
-				feed, err := fp.ParseURL(info.URL)
-				if err != nil {
-					log.Printf("Failed to parse feed %s during initialization: %v", info.Name, err)
-				} else if len(feed.Items) > 0 {
-					lastPostLink = feed.Items[0].Link
-					if feed.Items[0].PublishedParsed != nil {
-						lastSentTime = *feed.Items[0].PublishedParsed
-					}
-				}
+func diffFields(before, after bson.M, prefix string, set, unset bson.M) {
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+			set[prefix+key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			unset[prefix+key] = ""
+		}
+	}
+}
 
-				feedResult := feedParseResult{
-					feed: Feed{
-						BlogName:       info.Name,
-						RssURL:         info.URL,
-						AddedAt:        now,
-						LastSentTime:   lastSentTime,
-						LastPostLink:   lastPostLink,
-						TotalPostsSent: 0,
-					},
-					err: err,
-				}
+// 피드를 rssUrl arrayFilters 로 고쳐서 처리하는 사이 바뀐 피드 목록을 덮어쓰지 않는다.
+func channelProgressUpdate(before, after DiscordChannel) (bson.M, []any, error) {
+	set, unset, inc := bson.M{}, bson.M{}, bson.M{}
+	var arrayFilters []any
 
-				feedResults <- feedResult
-				time.Sleep(100 * time.Millisecond)
-			}(feedInfo)
-		}
+	beforeFields, err := documentFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := documentFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, fields := range []bson.M{beforeFields, afterFields} {
+		delete(fields, "_id")
+		delete(fields, "feeds")
+	}
+	diffFields(beforeFields, afterFields, "", set, unset)
 
-		go func() {
-			feedWg.Wait()
-			close(feedResults)
-		}()
+	previousFeeds := make(map[string]Feed, len(before.Feeds))
+	for _, feed := range before.Feeds {
+		previousFeeds[feed.RssURL] = feed
+	}
 
-		for result := range feedResults {
-			channel.Feeds = append(channel.Feeds, result.feed)
+	for i, feed := range after.Feeds {
+		previous, ok := previousFeeds[feed.RssURL]
+		if !ok {
+			continue
 		}
 
-		_, err = channelCollection.InsertOne(ctx, channel)
+		previousFields, err := documentFields(previous)
 		if err != nil {
-			log.Printf("Failed to create channel document for %s: %v", channelID, err)
-		} else {
-			log.Printf("Initialized default channel: %s", channelID)
+			return nil, nil, err
+		}
+		feedFields, err := documentFields(feed)
+		if err != nil {
+			return nil, nil, err
+		}
+		delete(previousFields, "totalPostsSent")
+		delete(feedFields, "totalPostsSent")
+
+		identifier := fmt.Sprintf("f%d", i)
+		prefix := "feeds.$[" + identifier + "]."
+		changes := len(set) + len(unset) + len(inc)
+		diffFields(previousFields, feedFields, prefix, set, unset)
+		if sent := feed.TotalPostsSent - previous.TotalPostsSent; sent != 0 {
+			inc[prefix+"totalPostsSent"] = sent
+		}
+		// 쓰지 않는 식별자가 arrayFilters 에 있으면 MongoDB 가 업데이트를 거부한다
+		if len(set)+len(unset)+len(inc) > changes {
+			arrayFilters = append(arrayFilters, bson.M{identifier + ".rssUrl": feed.RssURL})
 		}
 	}
 
-	return nil
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+	if len(update) == 0 {
+		return nil, nil, nil
+	}
+	return update, arrayFilters, nil
 }
 
-func processChannelFeeds(ctx context.Context, channel DiscordChannel, fp *gofeed.Parser) channelProcessResult {
-	channelNewItemsCount := 0
-	needsUpdate := false
+func saveChannelProgress(ctx context.Context, channelCollection channelUpdater, before, after DiscordChannel) error {
+	update, arrayFilters, err := channelProgressUpdate(before, after)
+	if err != nil || update == nil {
+		return err
+	}
 
-	for i, feedConfig := range channel.Feeds {
-		var feed *gofeed.Feed
-		var err error
+	opts := options.Update()
+	if len(arrayFilters) > 0 {
+		opts.SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+	}
+	return withMongoRetry(ctx, func() error {
+		_, err := channelCollection.UpdateOne(ctx, bson.M{"_id": after.ID}, update, opts)
+		return err
+	})
+}
 
-		for retry := range 3 {
-			feed, err = fp.ParseURLWithContext(feedConfig.RssURL, ctx)
-			if err == nil {
-				break
-			}
+// 캐시된 클라이언트는 Disconnect 하지 않고, 앞선 작업이 실패했을 때만 다시 Ping 한다.
+func connectMongoDB(ctx context.Context) (*mongo.Client, error) {
+	mongoClientMu.Lock()
+	cached, suspect := cachedMongoClient, mongoClientSuspect
+	mongoClientMu.Unlock()
 
-			if retry < 2 {
-				waitTime := time.Duration((retry+1)*2) * time.Second
-				log.Printf("Failed to parse feed %s (attempt %d/3): %v. Retrying in %v", feedConfig.BlogName, retry+1, err, waitTime)
-				time.Sleep(waitTime)
+	if cached != nil && !suspect {
+		return cached, nil
+	}
+	if cached != nil {
+		err := pingMongoDB(ctx, cached)
+		if err == nil {
+			mongoClientMu.Lock()
+			if cachedMongoClient == cached {
+				mongoClientSuspect = false
 			}
+			mongoClientMu.Unlock()
+			return cached, nil
 		}
+		log.Printf("Cached MongoDB client failed to ping, reconnecting: %v", err)
+	}
 
-		if err != nil {
-			log.Printf("Failed to parse feed %s after 3 attempts: %v", feedConfig.BlogName, err)
-			continue
-		}
+	mongoURI, err := getMongoURI(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		time.Sleep(250 * time.Millisecond)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	}
 
-		firstItem := true
-		for _, item := range feed.Items {
-			if feedConfig.LastPostLink == item.Link {
-				break
-			}
+	err = withMongoRetry(ctx, func() error {
+		return pingMongoDB(ctx, client)
+	})
+	if err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping MongoDB: %v", err)
+	}
 
-			if item.PublishedParsed != nil && item.PublishedParsed.Before(feedConfig.LastSentTime) {
-				continue
-			}
+	mongoClientMu.Lock()
+	if cachedMongoClient != nil && cachedMongoClient != cached && !mongoClientSuspect {
+		// 그 사이 다른 고루틴이 먼저 새로 연결했다
+		winner := cachedMongoClient
+		mongoClientMu.Unlock()
+		client.Disconnect(ctx)
+		return winner, nil
+	}
+	cachedMongoClient = client
+	mongoClientSuspect = false
+	mongoClientMu.Unlock()
 
-			content := fmt.Sprintf(
-				"📝 %s\n**🚀 %s**\n🔗 %s",
-				feedConfig.BlogName,
-				item.Title,
-				item.Link,
-			)
+	if cached != nil {
+		cached.Disconnect(ctx)
+	}
+	return client, nil
+}
 
-			err := sendDiscordMessage(channel.ID, content)
-			if err != nil {
-				log.Printf("Failed to send Discord message for item %s to channel %s: %v", item.Title, channel.ID, err)
-				continue
-			}
+func markMongoClientSuspect() {
+	mongoClientMu.Lock()
+	defer mongoClientMu.Unlock()
+	if cachedMongoClient != nil {
+		mongoClientSuspect = true
+	}
+}
 
-			if firstItem {
-				channel.Feeds[i].LastPostLink = item.Link
-				firstItem = false
-			}
-			channel.Feeds[i].LastSentTime = time.Now()
-			channel.Feeds[i].TotalPostsSent++
+func defaultUserAgent() string {
+	if userAgent := os.Getenv("FEED_USER_AGENT"); userAgent != "" {
+		return userAgent
+	}
+	return DefaultUserAgent
+}
 
-			channelNewItemsCount++
-			needsUpdate = true
-			time.Sleep(500 * time.Millisecond)
-		}
+// file://, gopher:// 같은 스킴으로 피드를 가져오지 않도록 막는다.
+func validateFeedURL(feedURL string) error {
+	parsed, err := url.Parse(strings.TrimSpace(feedURL))
+	if err != nil {
+		return fmt.Errorf("invalid feed URL: %v", err)
 	}
 
-	return channelProcessResult{
-		channel:     channel,
-		newItems:    channelNewItemsCount,
-		needsUpdate: needsUpdate,
-		err:         nil,
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported feed URL scheme: %q", parsed.Scheme)
+	}
+
+	if parsed.Hostname() == "" {
+		return fmt.Errorf("feed URL has no host")
 	}
+
+	return nil
 }
 
-func fetchAndProcessFeeds(ctx context.Context, client *mongo.Client) (int, error) {
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+// 링크 로컬에는 AWS 메타데이터 주소 169.254.169.254 도 들어간다.
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// DNS 조회 뒤의 실제 주소로 내부망 접속을 막는다 (ALLOW_PRIVATE_FEED_TARGETS=true 로 끈다).
+func newFeedDialer() *net.Dialer {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if os.Getenv("ALLOW_PRIVATE_FEED_TARGETS") == "true" {
+		return dialer
 	}
 
-	fp := gofeed.NewParser()
-	fp.Client = httpClient
-	fp.UserAgent = "Mozilla/5.0 (compatible; FeedNyang/1.0; +https://github.com/nmin11/feednyang)"
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("invalid dial address %s: %v", address, err)
+		}
 
-	channelCollection := client.Database("feednyang").Collection("discord_channels")
+		ip := net.ParseIP(host)
+		if ip == nil || isPrivateAddress(ip) {
+			return fmt.Errorf("refusing to connect to private address %s", host)
+		}
 
-	err := ensureDefaultChannels(ctx, channelCollection, fp)
-	if err != nil {
-		log.Printf("Failed to ensure default channels: %v", err)
+		return nil
 	}
 
-	totalNewItemsCount := 0
+	return dialer
+}
 
-	cursor, err := channelCollection.Find(ctx, bson.M{})
-	if err != nil {
-		return totalNewItemsCount, fmt.Errorf("failed to find channels: %v", err)
+// 프록시를 거치면 다이얼러에는 프록시 주소만 보이므로 목적지는 여기서 검사한다.
+func feedProxy(proxyConfig *httpproxy.Config) func(*http.Request) (*url.URL, error) {
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyFunc(req.URL)
+		if err != nil || proxyURL == nil || os.Getenv("ALLOW_PRIVATE_FEED_TARGETS") == "true" {
+			return proxyURL, err
+		}
+
+		if err := checkPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
 	}
-	defer cursor.Close(ctx)
+}
 
-	var channels []DiscordChannel
-	if err = cursor.All(ctx, &channels); err != nil {
-		return totalNewItemsCount, fmt.Errorf("failed to decode channels: %v", err)
+func checkPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", host, err)
 	}
 
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 3)
-	results := make(chan channelProcessResult, len(channels))
+	for _, addr := range addrs {
+		if isPrivateAddress(addr.IP) {
+			return fmt.Errorf("refusing to connect to private address %s (%s)", addr.IP, host)
+		}
+	}
+	return nil
+}
 
-	for _, channel := range channels {
-		wg.Add(1)
-		go func(ch DiscordChannel) {
-			defer wg.Done()
+// 사설망에 있는 사내 프록시로 가는 연결만 검사 없이 연다.
+func feedDialContext(proxyConfig *httpproxy.Config) func(ctx context.Context, network, address string) (net.Conn, error) {
+	proxyAddresses := make(map[string]bool)
+	for _, rawProxy := range []string{proxyConfig.HTTPProxy, proxyConfig.HTTPSProxy} {
+		if address := proxyAddress(rawProxy); address != "" {
+			proxyAddresses[address] = true
+		}
+	}
 
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+	guarded := newFeedDialer()
+	direct := &net.Dialer{Timeout: guarded.Timeout}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if proxyAddresses[address] {
+			return direct.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
 
-			result := processChannelFeeds(ctx, ch, fp)
-			results <- result
-		}(channel)
+// http.Transport 가 실제로 접속하는 host:port 로 바꾼다.
+func proxyAddress(rawProxy string) string {
+	if rawProxy == "" {
+		return ""
 	}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	proxyURL, err := url.Parse(rawProxy)
+	if err != nil || proxyURL.Host == "" {
+		// 스킴 없이 적은 값은 httpproxy 처럼 http 프록시로 본다
+		if proxyURL, err = url.Parse("http://" + rawProxy); err != nil {
+			return ""
+		}
+	}
 
-	for result := range results {
-		if result.err != nil {
-			log.Printf("Error processing channel %s: %v", result.channel.ID, result.err)
-			continue
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
 		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
 
-		if result.needsUpdate {
-			result.channel.UpdatedAt = time.Now()
-			_, err = channelCollection.ReplaceOne(ctx, bson.M{"_id": result.channel.ID}, result.channel)
-			if err != nil {
-				log.Printf("Failed to update channel document for %s: %v", result.channel.ID, err)
-			}
+// 로고가 없으면 사이트의 /favicon.ico 를 쓴다.
+func feedSiteInfo(feed *gofeed.Feed, feedURL string) (string, string) {
+	site, err := url.Parse(feed.Link)
+	if err != nil || (site.Scheme != "http" && site.Scheme != "https") || site.Host == "" {
+		site, err = url.Parse(feedURL)
+		if err != nil || site.Host == "" {
+			return "", ""
 		}
+		site = &url.URL{Scheme: site.Scheme, Host: site.Host}
+	}
 
-		totalNewItemsCount += result.newItems
-		log.Printf("Processed %d new items for channel %s", result.newItems, result.channel.ID)
+	iconURL := (&url.URL{Scheme: site.Scheme, Host: site.Host, Path: "/favicon.ico"}).String()
+	if feed.Image != nil && strings.HasPrefix(feed.Image.URL, "http") {
+		iconURL = feed.Image.URL
 	}
 
-	return totalNewItemsCount, nil
+	return site.String(), iconURL
 }
 
-func handleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, error) {
-	client, err := connectMongoDB(ctx)
+// userAgent 가 비어 있으면 파서의 기본 User-Agent 를 쓴다.
+func fetchFeed(ctx context.Context, fp *gofeed.Parser, feedURL string, userAgent string) (*gofeed.Feed, error) {
+	if err := validateFeedURL(feedURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if userAgent == "" {
+		userAgent = fp.UserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := fp.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, gofeed.HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxFeedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed body: %v", err)
+	}
+
+	// 일부 서버는 Content-Encoding 헤더 없이 gzip 으로 압축된 본문을 내려준다
+	if bytes.HasPrefix(body, gzipMagic) {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzipped feed body: %v", err)
+		}
+		body, err = io.ReadAll(io.LimitReader(gz, MaxFeedBodySize))
+		gz.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress feed body: %v", err)
+		}
+	}
+
+	// Content-Type 은 믿지 않고 (application/octet-stream, text/plain 등) 본문 앞부분으로 피드 여부를 판단한다
+	if !looksLikeFeed(body) {
+		// 인증이 필요해진 피드는 보통 다른 호스트의 SSO 로그인 페이지로 리다이렉트되어 HTML 을 200 으로 돌려준다
+		if finalURL := resp.Request.URL; finalURL != nil && !strings.EqualFold(finalURL.Hostname(), req.URL.Hostname()) {
+			return nil, fmt.Errorf("%w: redirected to %s", errFeedLoginRequired, finalURL.Redacted())
+		}
+		return nil, fmt.Errorf("response is not a feed (Content-Type: %s)", resp.Header.Get("Content-Type"))
+	}
+
+	body, err = decodeFeedBody(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := fp.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range feed.Items {
+		item.Title = cleanTitle(item.Title)
+	}
+
+	return feed, nil
+}
+
+// 선언된 charset 이 EUC-KR 같은 레거시 인코딩이면 UTF-8 로 바꾼다.
+func decodeFeedBody(body []byte, contentType string) ([]byte, error) {
+	head := body[:min(len(body), 1024)]
+
+	label := ""
+	if match := xmlEncodingPattern.FindSubmatch(head); match != nil {
+		label = string(match[1])
+	} else if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		label = params["charset"]
+	}
+	if label == "" {
+		return body, nil
+	}
+
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return body, nil
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return body, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s feed body: %v", label, err)
+	}
+
+	// gofeed 가 XML 선언의 인코딩으로 한 번 더 디코딩하지 않도록 선언도 UTF-8 로 고친다
+	if loc := xmlEncodingPattern.FindSubmatchIndex(decoded[:min(len(decoded), 1024)]); loc != nil {
+		decoded = append(append(append([]byte{}, decoded[:loc[2]]...), "UTF-8"...), decoded[loc[3]:]...)
+	}
+
+	return decoded, nil
+}
+
+// gofeed 의 공통 Feed 에는 ttl 이 없어서 Custom["ttl"] 로 남긴다.
+type ttlRSSTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *ttlRSSTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if rssFeed, ok := feed.(*rss.Feed); ok && strings.TrimSpace(rssFeed.TTL) != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom["ttl"] = strings.TrimSpace(rssFeed.TTL)
+	}
+
+	return result, nil
+}
+
+// <ttl> 이나 sy:updatePeriod 로 구한 폴링 간격(분)이다. 힌트가 없으면 0 이다.
+func feedPollInterval(feed *gofeed.Feed) int {
+	minutes := 0
+	if ttl, err := strconv.Atoi(feed.Custom["ttl"]); err == nil && ttl > 0 {
+		minutes = ttl
+	} else if sy, ok := feed.Extensions["sy"]; ok {
+		periodMinutes := map[string]int{
+			"hourly":  60,
+			"daily":   24 * 60,
+			"weekly":  7 * 24 * 60,
+			"monthly": 30 * 24 * 60,
+			"yearly":  365 * 24 * 60,
+		}
+
+		period := "daily"
+		if values := sy["updatePeriod"]; len(values) > 0 {
+			period = strings.ToLower(strings.TrimSpace(values[0].Value))
+		}
+		frequency := 1
+		if values := sy["updateFrequency"]; len(values) > 0 {
+			if parsed, err := strconv.Atoi(strings.TrimSpace(values[0].Value)); err == nil && parsed > 0 {
+				frequency = parsed
+			}
+		}
+		if periodMinutes[period] > 0 {
+			minutes = periodMinutes[period] / frequency
+		}
+	}
+
+	if minutes == 0 {
+		return 0
+	}
+	return min(max(minutes, MinPollInterval), MaxPollInterval)
+}
+
+func looksLikeFeed(body []byte) bool {
+	head := bytes.TrimPrefix(body, utf8BOM)
+	head = bytes.TrimLeft(head, " \t\r\n")
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.ToLower(head)
+
+	if bytes.HasPrefix(head, []byte("{")) {
+		return true
+	}
+
+	for _, prefix := range []string{"<?xml", "<rss", "<feed", "<rdf:rdf"} {
+		if bytes.HasPrefix(head, []byte(prefix)) {
+			return true
+		}
+	}
+
+	// 루트 엘리먼트 앞에 주석이 있는 경우
+	if bytes.HasPrefix(head, []byte("<!--")) {
+		for _, root := range []string{"<rss", "<feed", "<rdf:rdf"} {
+			if bytes.Contains(head, []byte(root)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func fetchSecretString(ctx context.Context, secretID string) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	client := secretsmanager.NewFromConfig(cfg)
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret value: %v", err)
+	}
+
+	if output.SecretString == nil || *output.SecretString == "" {
+		return "", fmt.Errorf("secret %s has no string value", secretID)
+	}
+
+	return strings.TrimSpace(*output.SecretString), nil
+}
+
+func getDiscordBotToken(ctx context.Context) (string, error) {
+	secretARN := os.Getenv("DISCORD_BOT_TOKEN_SECRET_ARN")
+	if secretARN == "" {
+		botToken := os.Getenv("DISCORD_BOT_TOKEN")
+		if botToken == "" {
+			return "", fmt.Errorf("DISCORD_BOT_TOKEN environment variable not set")
+		}
+		return botToken, nil
+	}
+
+	botTokenMu.Lock()
+	defer botTokenMu.Unlock()
+
+	if cachedBotToken != "" {
+		return cachedBotToken, nil
+	}
+
+	botToken, err := fetchSecretString(ctx, secretARN)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Discord bot token from Secrets Manager: %v", err)
+	}
+
+	cachedBotToken = botToken
+	return cachedBotToken, nil
+}
+
+func invalidateDiscordBotToken() {
+	botTokenMu.Lock()
+	defer botTokenMu.Unlock()
+
+	cachedBotToken = ""
+}
+
+func isDiscordAuthError(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusUnauthorized
+}
+
+type discordSender interface {
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+}
+
+// 토큰이 교체되었을 수 있으니 인증에 실패하면 캐시를 비우고 한 번 더 시도한다.
+func withDiscordSession(ctx context.Context, request func(session discordSender) error) error {
+	if err := discordRateLimiter.wait(ctx); err != nil {
+		return err
+	}
+
+	err := runDiscordRequest(ctx, request)
+
+	if isDiscordAuthError(err) && os.Getenv("DISCORD_BOT_TOKEN_SECRET_ARN") != "" {
+		log.Printf("Discord rejected the cached bot token, re-fetching it from Secrets Manager")
+		invalidateDiscordBotToken()
+		err = runDiscordRequest(ctx, request)
+	}
+
+	return err
+}
+
+func runDiscordRequest(ctx context.Context, request func(session discordSender) error) error {
+	botToken, err := getDiscordBotToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	session, err := getDiscordSession(botToken)
+	if err != nil {
+		return err
+	}
+
+	return request(session)
+}
+
+// 시크릿이 바뀌어 토큰이 달라졌을 때만 세션을 새로 만든다.
+func getDiscordSession(botToken string) (discordSender, error) {
+	discordSessionMu.Lock()
+	defer discordSessionMu.Unlock()
+
+	if discordSession != nil && discordSessionToken == botToken {
+		return discordSession, nil
+	}
+
+	session, err := newDiscordSession(botToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Discord session: %v", err)
+	}
+
+	discordSession = session
+	discordSessionToken = botToken
+	return discordSession, nil
+}
+
+// 피드에서 가져온 제목의 @everyone 같은 멘션이 알림을 보내지 않도록 멘션은 모두 막는다.
+func sendDiscordMessage(ctx context.Context, channelID string, content string, suppressEmbeds bool) (*discordgo.Message, error) {
+	var first *discordgo.Message
+	for _, chunk := range splitForDiscord(content) {
+		messageSend := &discordgo.MessageSend{
+			Content:         chunk,
+			AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		}
+		if suppressEmbeds {
+			messageSend.Flags = discordgo.MessageFlagsSuppressEmbeds
+		}
+
+		var message *discordgo.Message
+		err := withDiscordSession(ctx, func(session discordSender) error {
+			var err error
+			message, err = session.ChannelMessageSendComplex(channelID, messageSend)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send Discord message: %w", err)
+		}
+
+		if first == nil {
+			first = message
+		}
+	}
+
+	return first, nil
+}
+
+// URL 이 두 메시지에 걸치지 않도록 줄, 띄어쓰기 순서로 나눈다.
+func splitForDiscord(content string) []string {
+	if utf8.RuneCountInString(content) <= DiscordMessageLimit {
+		return []string{content}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if utf8.RuneCountInString(line) <= DiscordMessageLimit {
+			lines = append(lines, line)
+			continue
+		}
+
+		var words []string
+		for _, word := range strings.Split(line, " ") {
+			runes := []rune(word)
+			for len(runes) > DiscordMessageLimit {
+				words = append(words, string(runes[:DiscordMessageLimit]))
+				runes = runes[DiscordMessageLimit:]
+			}
+			words = append(words, string(runes))
+		}
+		lines = append(lines, joinWithinLimit(words, " ")...)
+	}
+
+	return joinWithinLimit(lines, "\n")
+}
+
+// 공백뿐인 덩어리는 Discord 가 받지 않으므로 버린다.
+func joinWithinLimit(pieces []string, sep string) []string {
+	var chunks []string
+	current := ""
+	currentLength := 0
+	for i, piece := range pieces {
+		pieceLength := utf8.RuneCountInString(piece)
+		if i > 0 && currentLength+len(sep)+pieceLength > DiscordMessageLimit {
+			if strings.TrimSpace(current) != "" {
+				chunks = append(chunks, current)
+			}
+			current, currentLength = piece, pieceLength
+			continue
+		}
+		if i > 0 {
+			current += sep
+			currentLength += len(sep)
+		}
+		current += piece
+		currentLength += pieceLength
+	}
+	if strings.TrimSpace(current) != "" {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+func sendDiscordEmbed(ctx context.Context, channelID string, embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	var message *discordgo.Message
+	err := withDiscordSession(ctx, func(session discordSender) error {
+		var err error
+		message, err = session.ChannelMessageSendEmbed(channelID, embed)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send Discord embed: %w", err)
+	}
+
+	return message, nil
+}
+
+// 임베드 링크 권한이 없는 채널에서도 글이 빠지지 않도록 실패하면 텍스트로 다시 보낸다.
+func sendFeedPost(ctx context.Context, channelID string, embed *discordgo.MessageEmbed, content string, suppressEmbeds bool) (*discordgo.Message, error) {
+	if embed != nil {
+		message, err := sendDiscordEmbed(ctx, channelID, embed)
+		if err == nil {
+			return message, nil
+		}
+		log.Printf("Failed to send embed to channel %s, falling back to plain text: %v", channelID, err)
+	}
+
+	return sendDiscordMessage(ctx, channelID, content, suppressEmbeds)
+}
+
+func sendFeedIntro(ctx context.Context, channelID string, blogName string, imageURL string) error {
+	messageSend := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Description: fmt.Sprintf("📢 이제부터 **%s** 의 새 글을 알려준다냥~!", blogName),
+			Image:       &discordgo.MessageEmbedImage{URL: imageURL},
+		}},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+	}
+
+	err := withDiscordSession(ctx, func(session discordSender) error {
+		_, err := session.ChannelMessageSendComplex(channelID, messageSend)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send feed intro: %w", err)
+	}
+
+	return nil
+}
+
+// 스레드가 삭제되었거나 잠긴 채 보관된 경우다.
+func isThreadUnavailableError(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+
+	if restErr.Message != nil {
+		switch restErr.Message.Code {
+		case discordgo.ErrCodeUnknownChannel, discordgo.ErrCodePerformedOperationOnArchivedThread:
+			return true
+		}
+	}
+
+	return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+func threadName(blogName string) string {
+	runes := []rune(blogName)
+	if len(runes) > ThreadNameLimit {
+		return string(runes[:ThreadNameLimit])
+	}
+	return blogName
+}
+
+// 스레드가 없거나 더 이상 쓸 수 없으면 채널에 글을 올리고 스레드를 새로 연다.
+func sendFeedThreadMessage(ctx context.Context, channelID string, feedConfig *Feed, embed *discordgo.MessageEmbed, content string, suppressEmbeds bool) error {
+	if feedConfig.ThreadID != "" {
+		_, err := sendFeedPost(ctx, feedConfig.ThreadID, embed, content, suppressEmbeds)
+		if err == nil || !isThreadUnavailableError(err) {
+			return err
+		}
+
+		log.Printf("Thread %s for feed %s is unavailable, creating a new one: %v", feedConfig.ThreadID, feedConfig.BlogName, err)
+		feedConfig.ThreadID = ""
+	}
+
+	message, err := sendFeedPost(ctx, channelID, embed, content, suppressEmbeds)
+	if err != nil {
+		return err
+	}
+
+	var thread *discordgo.Channel
+	err = withDiscordSession(ctx, func(session discordSender) error {
+		var err error
+		thread, err = session.MessageThreadStartComplex(channelID, message.ID, &discordgo.ThreadStart{
+			Name:                threadName(feedConfig.BlogName),
+			AutoArchiveDuration: ThreadAutoArchiveDuration,
+		})
+		return err
+	})
+	if err != nil {
+		// 글은 이미 채널에 올라갔으니 전송은 성공으로 보고, 스레드는 다음 글에서 다시 만든다
+		log.Printf("Failed to start thread for feed %s in channel %s: %v", feedConfig.BlogName, channelID, err)
+		return nil
+	}
+
+	feedConfig.ThreadID = thread.ID
+	return nil
+}
+
+// 가져오지 못해도 기준 시각을 지금으로 둬서 이전 글이 한꺼번에 올라오지 않게 한다.
+func newDefaultFeed(ctx context.Context, fp *gofeed.Parser, name string, feedURL string) (Feed, error) {
+	now := time.Now()
+	newFeed := Feed{
+		BlogName:       name,
+		RssURL:         feedURL,
+		AddedAt:        now,
+		LastSentTime:   now,
+		TotalPostsSent: 0,
+	}
+
+	feed, err := fetchFeed(ctx, fp, feedURL, "")
+	if err != nil {
+		return newFeed, err
+	}
+
+	newFeed.SiteURL, newFeed.IconURL = feedSiteInfo(feed, feedURL)
+	if len(feed.Items) > 0 {
+		newFeed.LastPostLink = feed.Items[0].Link
+		if feed.Items[0].PublishedParsed != nil {
+			newFeed.LastSentTime = *feed.Items[0].PublishedParsed
+		}
+	}
+
+	return newFeed, nil
+}
+
+// 이미 있는 피드는 건드리지 않으므로 여러 번 실행해도 결과가 같다.
+func syncDefaultFeeds(ctx context.Context, channelCollection *mongo.Collection, fp *gofeed.Parser) (map[string]int, error) {
+	var channels []DiscordChannel
+	err := withMongoRetry(ctx, func() error {
+		cursor, err := channelCollection.Find(ctx, bson.M{"followsDefaults": true})
+		if err != nil {
+			return fmt.Errorf("failed to find channels following defaults: %w", err)
+		}
+		defer cursor.Close(ctx)
+
+		return cursor.All(ctx, &channels)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 여러 채널에 빠진 피드도 한 번만 가져온다
+	defaultFeeds := make(map[string]Feed)
+	addedCounts := make(map[string]int)
+	for _, channel := range channels {
+		existing := make(map[string]bool)
+		for _, feed := range channel.Feeds {
+			existing[normalizeFeedURL(feed.RssURL)] = true
+		}
+
+		var missingFeeds []Feed
+
+		for _, info := range techBlogFeeds {
+			if existing[normalizeFeedURL(info.URL)] {
+				continue
+			}
+
+			feed, ok := defaultFeeds[info.URL]
+			if !ok {
+				feed, err = newDefaultFeed(ctx, fp, info.Name, info.URL)
+				if err != nil {
+					log.Printf("Failed to parse default feed %s while syncing: %v", info.Name, err)
+				}
+				defaultFeeds[info.URL] = feed
+			}
+
+			missingFeeds = append(missingFeeds, feed)
+			addedCounts[channel.ID]++
+		}
+
+		if addedCounts[channel.ID] == 0 {
+			continue
+		}
+
+		if err := pushFeeds(ctx, channelCollection, channel.ID, missingFeeds); err != nil {
+			log.Printf("Failed to sync default feeds into channel %s: %v", channel.ID, err)
+			delete(addedCounts, channel.ID)
+			continue
+		}
+		log.Printf("Synced %d default feeds into channel %s", addedCounts[channel.ID], channel.ID)
+	}
+
+	return addedCounts, nil
+}
+
+func ensureDefaultChannels(ctx context.Context, channelCollection *mongo.Collection, fp *gofeed.Parser) error {
+	defaultChannelIDs := os.Getenv("DEFAULT_DISCORD_CHANNEL_IDS")
+	if defaultChannelIDs == "" {
+		log.Println("No default channel IDs provided, skipping initialization")
+		return nil
+	}
+
+	channelIDs := strings.SplitSeq(defaultChannelIDs, ",")
+
+	for channelID := range channelIDs {
+		channelID = strings.TrimSpace(channelID)
+		if channelID == "" {
+			continue
+		}
+
+		count, err := channelCollection.CountDocuments(ctx, bson.M{"_id": channelID})
+		if err != nil {
+			log.Printf("Error checking channel %s: %v", channelID, err)
+			continue
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		channel := DiscordChannel{
+			ID:              channelID,
+			Feeds:           []Feed{},
+			FollowsDefaults: true,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+
+		var feedWg sync.WaitGroup
+		feedResults := make(chan feedParseResult, len(techBlogFeeds))
+
+		for _, feedInfo := range techBlogFeeds {
+			feedWg.Add(1)
+			go func(info struct{ Name, URL string }) {
+				defer feedWg.Done()
+
+				feed, err := newDefaultFeed(ctx, fp, info.Name, info.URL)
+				if err != nil {
+					log.Printf("Failed to parse feed %s during initialization: %v", info.Name, err)
+				}
+
+				feedResults <- feedParseResult{feed: feed, err: err}
+				time.Sleep(100 * time.Millisecond)
+			}(feedInfo)
+		}
+
+		go func() {
+			feedWg.Wait()
+			close(feedResults)
+		}()
+
+		for result := range feedResults {
+			channel.Feeds = append(channel.Feeds, result.feed)
+		}
+
+		_, err = channelCollection.InsertOne(ctx, channel)
+		if err != nil {
+			log.Printf("Failed to create channel document for %s: %v", channelID, err)
+		} else {
+			log.Printf("Initialized default channel: %s", channelID)
+		}
+	}
+
+	return nil
+}
+
+// 예: Medium 의 ?source=rss----f107b03c406e---4
+func normalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.RawQuery == "" {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	stripTrackingParams(query)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+// Medium 의 source=rss... 처럼 가져올 때마다 바뀌는 추적용 파라미터를 지운다.
+func stripTrackingParams(query url.Values) {
+	for key, values := range query {
+		lowerKey := strings.ToLower(key)
+		if strings.HasPrefix(lowerKey, "utm_") {
+			query.Del(key)
+			continue
+		}
+		if lowerKey == "source" && len(values) > 0 && strings.HasPrefix(values[0], "rss") {
+			query.Del(key)
+		}
+	}
+}
+
+// Command Lambda 의 normalizeFeedURL 과 같은 규칙을 유지해야 한다.
+func normalizeFeedURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	stripTrackingParams(query)
+
+	normalized := strings.ToLower(parsed.Host) + strings.TrimSuffix(parsed.Path, "/")
+	if encodedQuery := query.Encode(); encodedQuery != "" {
+		normalized += "?" + encodedQuery
+	}
+	return normalized
+}
+
+// 차단 키워드가 필터 키워드보다 먼저다.
+func itemSkipReason(item *gofeed.Item, feedConfig Feed, languageFilter string) string {
+	if keyword, blocked := matchKeyword(item, feedConfig.BlockKeywords); blocked {
+		return fmt.Sprintf("matched block keyword %q", keyword)
+	}
+	if !matchesLanguage(item, languageFilter) {
+		return fmt.Sprintf("filtered by language %q", languageFilter)
+	}
+	if !matchesCategories(item, feedConfig.RequireCategories) {
+		return "missing required category"
+	}
+	if _, matched := matchKeyword(item, feedConfig.Keywords); len(feedConfig.Keywords) > 0 && !matched {
+		return "matched no filter keyword"
+	}
+	return ""
+}
+
+func matchKeyword(item *gofeed.Item, keywords []string) (string, bool) {
+	if len(keywords) == 0 {
+		return "", false
+	}
+
+	text := strings.ToLower(item.Title + "\n" + item.Description)
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return keyword, true
+		}
+	}
+
+	return "", false
+}
+
+func matchesCategories(item *gofeed.Item, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		for _, want := range required {
+			if strings.EqualFold(category, want) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// 제목이 없으면 요약을 본다.
+func isKoreanItem(item *gofeed.Item) bool {
+	text := item.Title
+	if strings.TrimSpace(text) == "" {
+		text = item.Description
+	}
+
+	for _, r := range text {
+		if unicode.Is(unicode.Hangul, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesLanguage(item *gofeed.Item, languageFilter string) bool {
+	switch languageFilter {
+	case LanguageFilterKorean:
+		return isKoreanItem(item)
+	case LanguageFilterNonKorean:
+		return !isKoreanItem(item)
+	default:
+		return true
+	}
+}
+
+func sendOrder() string {
+	switch order := os.Getenv("FEED_SEND_ORDER"); order {
+	case SendOrderAlpha, SendOrderRoundRobin:
+		return order
+	default:
+		return SendOrderInsertion
+	}
+}
+
+func isBeforeLastSent(item *gofeed.Item, lastSentTime time.Time, window time.Duration) bool {
+	return item.PublishedParsed != nil && item.PublishedParsed.Before(lastSentTime.Add(-window))
+}
+
+// 최소 나이를 기다리는 글이 다음 실행에서 지나간 글로 걸러지지 않게 한다.
+func sentTimeAfterDelivery(now, oldestWaiting time.Time) time.Time {
+	if !oldestWaiting.IsZero() && oldestWaiting.Before(now) {
+		return oldestWaiting
+	}
+	return now
+}
+
+// 서버 시계 차이나 피드 캐시로 발행 시각이 살짝 과거로 찍힌 새 글을 놓치지 않게 한다.
+func graceWindow() time.Duration {
+	value := os.Getenv("FEED_GRACE_WINDOW")
+	if value == "" {
+		return DefaultGraceWindow
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		log.Printf("Invalid FEED_GRACE_WINDOW %q, using default %v", value, DefaultGraceWindow)
+		return DefaultGraceWindow
+	}
+
+	return window
+}
+
+// n 번째 재시도 전에는 기준값의 n 배만큼 기다린다.
+func loadFeedRetryConfig() (int, time.Duration) {
+	attempts := DefaultFeedRetryAttempts
+	if value := os.Getenv("FEED_RETRY_ATTEMPTS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			log.Printf("Invalid FEED_RETRY_ATTEMPTS %q, using default %d", value, DefaultFeedRetryAttempts)
+		} else {
+			attempts = parsed
+		}
+	}
+
+	baseDelay := DefaultFeedRetryBaseDelay
+	if value := os.Getenv("FEED_RETRY_BASE_DELAY"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid FEED_RETRY_BASE_DELAY %q, using default %v", value, DefaultFeedRetryBaseDelay)
+		} else {
+			baseDelay = parsed
+		}
+	}
+
+	return attempts, baseDelay
+}
+
+// 모든 요청은 discordRateLimiter 를 거치므로 늘려도 Discord 전역 한도는 넘지 않는다.
+func sendConcurrency() int {
+	value := os.Getenv("CHANNEL_SEND_CONCURRENCY")
+	if value == "" {
+		return DefaultSendConcurrency
+	}
+
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		log.Printf("Invalid CHANNEL_SEND_CONCURRENCY %q, using default %d", value, DefaultSendConcurrency)
+		return DefaultSendConcurrency
+	}
+
+	return concurrency
+}
+
+// 배포 공백 뒤 기준 없는 피드가 일주일치 글을 한꺼번에 보내지 않게 한다.
+func initialCatchupWindow() time.Duration {
+	value := os.Getenv("INITIAL_CATCHUP_WINDOW")
+	if value == "" {
+		return DefaultCatchupWindow
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		log.Printf("Invalid INITIAL_CATCHUP_WINDOW %q, using default %v", value, DefaultCatchupWindow)
+		return DefaultCatchupWindow
+	}
+
+	return window
+}
+
+// Lambda 가 전송 도중에 타임아웃으로 끊기지 않도록 나머지는 다음 실행으로 넘긴다.
+func maxSendsPerRun() int {
+	value := os.Getenv("MAX_SENDS_PER_RUN")
+	if value == "" {
+		return DefaultMaxSendsPerRun
+	}
+
+	maxSends, err := strconv.Atoi(value)
+	if err != nil || maxSends < 1 {
+		log.Printf("Invalid MAX_SENDS_PER_RUN %q, using default %d", value, DefaultMaxSendsPerRun)
+		return DefaultMaxSendsPerRun
+	}
+
+	return maxSends
+}
+
+// 블로그 이전 등으로 링크가 모두 바뀐 것으로 볼 새 글 수다.
+func formatChangeThreshold() int {
+	value := os.Getenv("FEED_FORMAT_CHANGE_THRESHOLD")
+	if value == "" {
+		return DefaultFormatChangeThreshold
+	}
+
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold < 1 {
+		log.Printf("Invalid FEED_FORMAT_CHANGE_THRESHOLD %q, using default %d", value, DefaultFormatChangeThreshold)
+		return DefaultFormatChangeThreshold
+	}
+
+	return threshold
+}
+
+func buildPostQueues(feeds []Feed, queues [][]*gofeed.Item, deliveryMode string, burstThreshold int) [][]pendingPost {
+	postQueues := make([][]pendingPost, len(queues))
+	for i, items := range queues {
+		// 오래 멈췄던 피드가 글을 한꺼번에 쏟아내면 최신 글 몇 개만 묶어서 보여주고 나머지는 건너뛴다
+		if burstThreshold > 0 && len(items) > burstThreshold {
+			postQueues[i] = []pendingPost{{feedIndex: i, items: items[:min(BurstPreviewCount, len(items))], burstTotal: len(items)}}
+			continue
+		}
+
+		if deliveryMode == DeliveryModeSummary && len(items) > 1 {
+			for _, chunk := range splitSummaryItems(feeds[i].BlogName, items) {
+				postQueues[i] = append(postQueues[i], pendingPost{feedIndex: i, items: chunk})
+			}
+			continue
+		}
+
+		for _, item := range items {
+			postQueues[i] = append(postQueues[i], pendingPost{feedIndex: i, items: []*gofeed.Item{item}})
+		}
+	}
+	return postQueues
+}
+
+func summaryLine(item *gofeed.Item) string {
+	return fmt.Sprintf("• [%s](<%s>)\n", item.Title, item.Link)
+}
+
+// 나뉜 메시지마다 피드 이름 머리말이 붙는다.
+func splitSummaryItems(blogName string, items []*gofeed.Item) [][]*gofeed.Item {
+	headerLength := utf8.RuneCountInString(fmt.Sprintf("📚 **%s**: 새 글 %d개다냥~\n", blogName, len(items)))
+
+	var chunks [][]*gofeed.Item
+	var chunk []*gofeed.Item
+	length := headerLength
+	for _, item := range items {
+		lineLength := utf8.RuneCountInString(summaryLine(item))
+		if len(chunk) > 0 && length+lineLength > DiscordMessageLimit {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			length = headerLength
+		}
+		chunk = append(chunk, item)
+		length += lineLength
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// 각 피드 안에서는 피드에 나온 순서(최신 글 먼저)를 유지한다.
+func orderPendingPosts(feeds []Feed, postQueues [][]pendingPost, order string) []pendingPost {
+	feedIndexes := make([]int, len(feeds))
+	for i := range feeds {
+		feedIndexes[i] = i
+	}
+
+	if order == SendOrderAlpha {
+		sort.SliceStable(feedIndexes, func(a, b int) bool {
+			return strings.ToLower(feeds[feedIndexes[a]].BlogName) < strings.ToLower(feeds[feedIndexes[b]].BlogName)
+		})
+	}
+
+	var posts []pendingPost
+	if order == SendOrderRoundRobin {
+		// 한 피드의 밀린 글이 채널 상단을 도배하지 않도록 피드마다 하나씩 번갈아 보낸다
+		for round := 0; ; round++ {
+			added := false
+			for _, i := range feedIndexes {
+				if round < len(postQueues[i]) {
+					posts = append(posts, postQueues[i][round])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+		return posts
+	}
+
+	for _, i := range feedIndexes {
+		posts = append(posts, postQueues[i]...)
+	}
+	return posts
+}
+
+// gofeed 는 CDATA 안의 이스케이프된 태그를 그대로 두므로 그럴 때만 풀어서 지운다.
+func cleanTitle(title string) string {
+	if !escapedHTMLPattern.MatchString(title) {
+		return title
+	}
+
+	// &amp;lt; 처럼 한 겹 더 감싼 경우까지 푼다
+	text := html.UnescapeString(html.UnescapeString(title))
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func plainText(body string) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(body, " "))
+	// 이스케이프된 HTML 은 한 번 풀면 태그가 글자로 드러나므로 한 번 더 지우고 푼다
+	if escapedHTMLPattern.MatchString(body) {
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit <= 0 {
+		return ""
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+func plainTextDescription(description string) string {
+	text := plainText(description)
+
+	runes := []rune(text)
+	if len(runes) > MaxDescriptionLength {
+		return string(runes[:MaxDescriptionLength]) + "…"
+	}
+	return text
+}
+
+// 본문이 없는 피드는 요약을 대신 쓴다.
+func itemBody(item *gofeed.Item) string {
+	if item.Content != "" {
+		return item.Content
+	}
+	return item.Description
+}
+
+func buildFeedEmbed(feedConfig Feed, item *gofeed.Item) *discordgo.MessageEmbed {
+	description := item.Description
+	if feedConfig.IncludeContent {
+		description = itemBody(item)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       truncateText(item.Title, EmbedTitleLimit),
+		URL:         item.Link,
+		Description: truncateText(plainText(description), EmbedDescriptionLimit),
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    feedConfig.BlogName,
+			URL:     feedConfig.SiteURL,
+			IconURL: feedConfig.IconURL,
+		},
+	}
+	if item.PublishedParsed != nil {
+		embed.Timestamp = item.PublishedParsed.Format(time.RFC3339)
+	}
+
+	return embed
+}
+
+func buildPostContent(feedConfig Feed, post pendingPost, displayFields []string) string {
+	if post.burstTotal > 0 {
+		content := fmt.Sprintf("📚 **%s** 에 밀린 글 %d개가 올라왔다냥! 최신 %d개만 보여준다냥~\n", feedConfig.BlogName, post.burstTotal, len(post.items))
+		for _, item := range post.items {
+			content += fmt.Sprintf("• [%s](<%s>)\n", item.Title, item.Link)
+		}
+		return content
+	}
+
+	if len(post.items) == 1 {
+		item := post.items[0]
+		if len(displayFields) == 0 {
+			displayFields = defaultDisplayFields
+		}
+
+		content := fmt.Sprintf("📝 %s\n**🚀 %s**", feedConfig.BlogName, item.Title)
+		if slices.Contains(displayFields, DisplayFieldDate) && item.PublishedParsed != nil {
+			content += "\n📅 " + item.PublishedParsed.In(kst).Format("2006-01-02")
+		}
+		if slices.Contains(displayFields, DisplayFieldAuthor) && item.Author != nil && item.Author.Name != "" {
+			content += "\n✍️ " + item.Author.Name
+		}
+		// 본문을 보내는 피드는 요약이 본문과 겹치므로 요약을 따로 붙이지 않는다
+		if slices.Contains(displayFields, DisplayFieldDescription) && !feedConfig.IncludeContent {
+			if description := plainTextDescription(item.Description); description != "" {
+				content += "\n> " + description
+			}
+		}
+		var linkLine string
+		if slices.Contains(displayFields, DisplayFieldLink) {
+			linkLine = "\n🔗 " + item.Link
+		}
+		if feedConfig.IncludeContent {
+			// 링크는 잘리지 않도록 남겨두고, 메시지 제한에서 남는 만큼만 본문을 싣는다
+			remaining := DiscordMessageLimit - utf8.RuneCountInString(content+linkLine+"\n> ")
+			if body := truncateText(plainText(itemBody(item)), remaining); body != "" {
+				content += "\n> " + body
+			}
+		}
+		return content + linkLine
+	}
+
+	content := fmt.Sprintf("📚 **%s**: 새 글 %d개다냥~\n", feedConfig.BlogName, len(post.items))
+	for i, item := range post.items {
+		line := summaryLine(item)
+		remaining := len(post.items) - i
+		footer := fmt.Sprintf("…외 %d개", remaining)
+		// splitSummaryItems 로 나눈 메시지는 넘지 않는다. 글 하나가 제한보다 긴 경우에만 잘린다
+		if utf8.RuneCountInString(content+line+footer) > DiscordMessageLimit {
+			content += footer
+			break
+		}
+		content += line
+	}
+	return content
+}
+
+// 이번 실행 안에 기다리기엔 너무 많이 남았으면 false 를 돌려줘서 다음 실행으로 미룬다.
+func waitForPostSlot(ctx context.Context, channel DiscordChannel) bool {
+	interval := time.Duration(channel.MinPostInterval) * time.Second
+	wait := time.Until(channel.LastChannelPostAt.Add(interval))
+	if wait <= 0 {
+		return true
+	}
+
+	if wait > MaxChannelPostWait {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait+ChannelPostDeadlineMargin).After(deadline) {
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
+
+// 다음 실행에서 미룬 글을 다시 새 글로 잡도록 중복 확인 기준을 되돌린다.
+func deferPendingPosts(channel *DiscordChannel, originalFeeds []Feed, lastSentLinks []string, deferred []pendingPost) {
+	for _, post := range deferred {
+		i := post.feedIndex
+		channel.Feeds[i].LastSentTime = originalFeeds[i].LastSentTime
+		if lastSentLinks[i] != "" {
+			channel.Feeds[i].LastPostLink = lastSentLinks[i]
+		} else {
+			channel.Feeds[i].LastPostLink = originalFeeds[i].LastPostLink
+		}
+	}
+}
+
+func fetchFeedWithRetry(ctx context.Context, fp *gofeed.Parser, feedConfig Feed) (*gofeed.Feed, time.Duration, int, error) {
+	var feed *gofeed.Feed
+	var elapsed time.Duration
+	var err error
+	attempts := 0
+
+	for retry := range feedRetryAttempts {
+		attempts++
+		startedAt := time.Now()
+		feed, err = fetchFeed(ctx, fp, feedConfig.RssURL, feedConfig.UserAgent)
+		elapsed = time.Since(startedAt)
+		// 로그인 페이지로 넘어가는 피드는 다시 시도해도 결과가 같다
+		if err == nil || errors.Is(err, errFeedLoginRequired) {
+			break
+		}
+
+		if retry < feedRetryAttempts-1 {
+			waitTime := time.Duration(retry+1) * feedRetryBaseDelay
+			failureLog.record("feed fetches retried", feedConfig.BlogName, err)
+			time.Sleep(waitTime)
+		}
+	}
+
+	return feed, elapsed, attempts, err
+}
+
+// 지터로 매번 문서가 바뀌지 않도록 ParseLatencyResolution 단위로 반올림한다.
+func updateAvgParseMs(avgParseMs int64, elapsed time.Duration) int64 {
+	average := elapsed
+	if avgParseMs > 0 {
+		average = (time.Duration(avgParseMs)*time.Millisecond*(ParseLatencySmoothing-1) + elapsed) / ParseLatencySmoothing
+	}
+	return average.Round(ParseLatencyResolution).Milliseconds()
+}
+
+func updateAvgDeliverySeconds(avgSeconds int64, items []*gofeed.Item, sentAt time.Time) int64 {
+	for _, item := range items {
+		if item.PublishedParsed == nil {
+			continue
+		}
+		latency := int64(max(sentAt.Sub(*item.PublishedParsed), 0) / time.Second)
+		if avgSeconds <= 0 {
+			avgSeconds = latency
+			continue
+		}
+		avgSeconds = (avgSeconds*(ParseLatencySmoothing-1) + latency) / ParseLatencySmoothing
+	}
+	return avgSeconds
+}
+
+// 에러 메시지에 섞인 URL 등을 빼고 원인만 짧게 분류한다.
+func failureReason(err error) string {
+	if errors.Is(err, errFeedLoginRequired) {
+		return "login required"
+	}
+
+	var httpErr gofeed.HTTPError
+	if errors.As(err, &httpErr) {
+		return fmt.Sprintf("HTTP %d", httpErr.StatusCode)
+	}
+
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "Client.Timeout"), strings.Contains(message, "deadline exceeded"), strings.Contains(message, "i/o timeout"):
+		return "timeout"
+	case strings.Contains(message, "no such host"):
+		return "DNS lookup failure"
+	case strings.Contains(message, "connection refused"):
+		return "connection refused"
+	case strings.Contains(message, "connection reset"):
+		return "connection reset"
+	case strings.Contains(message, "response is not a feed"):
+		return "non-feed response"
+	default:
+		return "other error"
+	}
+}
+
+func (a *failureLogAggregator) record(event string, subject string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.counts == nil {
+		a.counts = make(map[string]int)
+		a.samples = make(map[string][]string)
+	}
+
+	key := fmt.Sprintf("%s with %s", event, failureReason(err))
+	if a.counts[key] == 0 {
+		a.order = append(a.order, key)
+		// 원인별 첫 번째 에러는 전체 메시지를 바로 남겨서 분류에 없는 원인도 알 수 있게 한다
+		log.Printf("%s: %s: %v", key, subject, err)
+	}
+	a.counts[key]++
+	if len(a.samples[key]) < 3 {
+		a.samples[key] = append(a.samples[key], subject)
+	}
+}
+
+// Lambda 가 재사용되므로 실행이 끝날 때마다 불러야 한다.
+func (a *failureLogAggregator) flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, key := range a.order {
+		log.Printf("%d %s (e.g. %s)", a.counts[key], key, strings.Join(a.samples[key], ", "))
+	}
+
+	a.counts = nil
+	a.samples = nil
+	a.order = nil
+}
+
+// 스케줄 실행 시각이 조금씩 밀리므로 PollIntervalSlack 만큼 여유를 둔다.
+func isFeedPollDue(feedConfig Feed, now time.Time) bool {
+	if feedConfig.PollInterval <= 0 || feedConfig.LastCheckedAt.IsZero() {
+		return true
+	}
+
+	interval := time.Duration(feedConfig.PollInterval) * time.Minute
+	return now.Sub(feedConfig.LastCheckedAt)+PollIntervalSlack >= interval
+}
+
+// 시작이 끝보다 늦으면 (예: 22 ~ 6) 자정을 넘기는 시간대로 본다.
+func isWithinActiveHours(feedConfig Feed, now time.Time) bool {
+	start, end := feedConfig.ActiveHoursStart, feedConfig.ActiveHoursEnd
+	if start == end {
+		return true
+	}
+
+	hour := now.In(kst).Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// Lambda 는 메모리에 비례해서 vCPU 를 주므로 runtime.NumCPU() 로 함수 크기에 맞춘다.
+func workerCount(envName string, perCPU int) int {
+	defaultWorkers := runtime.NumCPU() * perCPU
+
+	value := os.Getenv(envName)
+	if value == "" {
+		return defaultWorkers
+	}
+
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 1 {
+		log.Printf("Invalid %s %q, using default %d", envName, value, defaultWorkers)
+		return defaultWorkers
+	}
+
+	return workers
+}
+
+// 네트워크를 기다리는 시간이 대부분이라 vCPU 당 여러 개를 둔다.
+func feedWorkerCount() int {
+	return workerCount("FEED_WORKERS", FeedWorkersPerCPU)
+}
+
+func channelWorkerCount() int {
+	return workerCount("CHANNEL_WORKERS", ChannelWorkersPerCPU)
+}
+
+// 피드가 많은 채널이 있어도 전역 워커 풀로 최대한 병렬로 가져온다.
+func fetchAllFeeds(ctx context.Context, fp *gofeed.Parser, channels []DiscordChannel) [][]feedFetchResult {
+	type feedJob struct {
+		channelIndex int
+		feedIndex    int
+	}
+
+	results := make([][]feedFetchResult, len(channels))
+	var jobs []feedJob
+	for channelIndex, channel := range channels {
+		results[channelIndex] = make([]feedFetchResult, len(channel.Feeds))
+		for feedIndex := range channel.Feeds {
+			jobs = append(jobs, feedJob{channelIndex: channelIndex, feedIndex: feedIndex})
+		}
+	}
+
+	jobQueue := make(chan feedJob, len(jobs))
+	for _, job := range jobs {
+		jobQueue <- job
+	}
+	close(jobQueue)
+
+	var wg sync.WaitGroup
+	for range min(feedWorkerCount(), len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range jobQueue {
+				feedConfig := channels[job.channelIndex].Feeds[job.feedIndex]
+				if feedConfig.Paused || !isFeedPollDue(feedConfig, time.Now()) {
+					results[job.channelIndex][job.feedIndex] = feedFetchResult{skipped: true}
+					continue
+				}
+
+				feed, elapsed, attempts, err := fetchFeedWithRetry(ctx, fp, feedConfig)
+				results[job.channelIndex][job.feedIndex] = feedFetchResult{feed: feed, err: err, elapsed: elapsed, attempts: attempts}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// 그 사이 /add 한 피드는 바로 가져오고 /remove 한 피드는 처리하지 않도록 다시 읽는다.
+func refreshChannel(ctx context.Context, channelCollection *mongo.Collection, fp *gofeed.Parser, snapshot DiscordChannel, fetched []feedFetchResult) (DiscordChannel, []feedFetchResult, bool) {
+	var channel DiscordChannel
+	err := channelCollection.FindOne(ctx, bson.M{"_id": snapshot.ID}).Decode(&channel)
+	if err == mongo.ErrNoDocuments {
+		log.Printf("Channel %s was removed during the run, skipping", snapshot.ID)
+		return snapshot, nil, false
+	}
+	if err != nil {
+		log.Printf("Failed to refresh channel %s, using the snapshot: %v", snapshot.ID, err)
+		return snapshot, fetched, true
+	}
+
+	fetchedByURL := make(map[string]feedFetchResult, len(snapshot.Feeds))
+	for i, feedConfig := range snapshot.Feeds {
+		fetchedByURL[feedConfig.RssURL] = fetched[i]
+	}
+
+	refreshed := make([]feedFetchResult, len(channel.Feeds))
+	for i, feedConfig := range channel.Feeds {
+		if result, ok := fetchedByURL[feedConfig.RssURL]; ok {
+			refreshed[i] = result
+			continue
+		}
+
+		if feedConfig.Paused || !isFeedPollDue(feedConfig, time.Now()) {
+			refreshed[i] = feedFetchResult{skipped: true}
+			continue
+		}
+		log.Printf("Fetching feed %s added to channel %s during the run", feedConfig.RssURL, channel.ID)
+		feed, elapsed, attempts, err := fetchFeedWithRetry(ctx, fp, feedConfig)
+		refreshed[i] = feedFetchResult{feed: feed, err: err, elapsed: elapsed, attempts: attempts}
+	}
+
+	return channel, refreshed, true
+}
+
+func processChannelFeeds(ctx context.Context, channel DiscordChannel, fetched []feedFetchResult, budget *sendBudget) channelProcessResult {
+	channelNewItemsCount := 0
+	needsUpdate := false
+	var failedSends []FailedSend
+	var feedFailures []feedFailure
+
+	queues := make([][]*gofeed.Item, len(channel.Feeds))
+	window := graceWindow()
+	catchupWindow := initialCatchupWindow()
+	formatChangeLimit := formatChangeThreshold()
+	var rebaselinedFeeds []int
+	pointerMoved := make([]bool, len(channel.Feeds))
+	oldestWaiting := make([]time.Time, len(channel.Feeds))
+	startingFeeds := slices.Clone(channel.Feeds)
+	var updatedPosts []pendingPost
+
+	for i, feedConfig := range channel.Feeds {
+		// 가져온 뒤에 일시정지한 피드도 기준 글을 옮기지 않고 그대로 둔다
+		if fetched[i].skipped || feedConfig.Paused {
+			continue
+		}
+
+		feed, err := fetched[i].feed, fetched[i].err
+		if err != nil {
+			failureLog.record(fmt.Sprintf("feeds failed after %d attempts", fetched[i].attempts), feedConfig.BlogName, err)
+			feedFailures = append(feedFailures, feedFailure{rssURL: feedConfig.RssURL, blogName: feedConfig.BlogName, reason: failureReason(err)})
+			channel.Feeds[i].ConsecutiveFailures++
+			channel.Feeds[i].LastError = FeedErrorFetchFailed
+			if errors.Is(err, errFeedLoginRequired) {
+				channel.Feeds[i].LastError = FeedErrorLoginRequired
+			}
+			needsUpdate = true
+			continue
+		}
+
+		if feedConfig.ConsecutiveFailures > 0 || feedConfig.LastError != "" {
+			channel.Feeds[i].ConsecutiveFailures = 0
+			channel.Feeds[i].LastError = ""
+			needsUpdate = true
+		}
+
+		// 한 번 느린 건 넘어가고, 여러 실행에 걸친 평균이 느린 피드만 경고한다
+		if avgParseMs := updateAvgParseMs(feedConfig.AvgParseMs, fetched[i].elapsed); avgParseMs != feedConfig.AvgParseMs {
+			channel.Feeds[i].AvgParseMs = avgParseMs
+			needsUpdate = true
+		}
+		if avgParseMs := time.Duration(channel.Feeds[i].AvgParseMs) * time.Millisecond; avgParseMs >= SlowFeedThreshold {
+			log.Printf("Slow feed %s (%s) in channel %s: average fetch time %v", feedConfig.BlogName, feedConfig.RssURL, channel.ID, avgParseMs)
+		}
+
+		// 폴링 간격은 매번 다시 읽는다. 힌트가 있는 피드만 확인 시각을 남겨서 나머지 채널 문서는 불필요하게 쓰지 않는다
+		if pollInterval := feedPollInterval(feed); pollInterval > 0 || feedConfig.PollInterval > 0 {
+			channel.Feeds[i].PollInterval = pollInterval
+			channel.Feeds[i].LastCheckedAt = time.Now()
+			needsUpdate = true
+		}
+
+		// 사이트 주소와 로고를 저장하기 전에 추가된 피드는 성공적으로 가져왔을 때 채워둔다
+		if feedConfig.SiteURL == "" {
+			if siteURL, iconURL := feedSiteInfo(feed, feedConfig.RssURL); siteURL != "" {
+				channel.Feeds[i].SiteURL = siteURL
+				channel.Feeds[i].IconURL = iconURL
+				needsUpdate = true
+			}
+		}
+
+		// 게시 시간대 밖이면 새 글을 모으지 않고 포인터도 그대로 둬서, 시간대가 열린 뒤 실행에서 보낸다
+		if !isWithinActiveHours(feedConfig, time.Now()) {
+			continue
+		}
+
+		var catchupCutoff time.Time
+		if feedConfig.LastPostLink == "" {
+			catchupCutoff = time.Now().Add(-catchupWindow)
+		}
+
+		pointerFound := false
+		waiting := false
+		minItemAge := time.Duration(feedConfig.MinItemAge) * time.Minute
+		for _, item := range feed.Items {
+			if normalizeURL(feedConfig.LastPostLink) == normalizeURL(item.Link) {
+				pointerFound = true
+				// 이미 보낸 글이 수정된 경우다. 새 글로 보내지 않고, 원하는 채널에만 수정 알림을 보낸다
+				if isItemUpdatedSince(item, feedConfig.LastPostUpdatedAt) {
+					if channel.UpdateNotices {
+						updatedPosts = append(updatedPosts, pendingPost{feedIndex: i, items: []*gofeed.Item{item}})
+					} else {
+						channel.Feeds[i].LastPostUpdatedAt = *item.UpdatedParsed
+						needsUpdate = true
+					}
+				}
+				break
+			}
+
+			if isBeforeLastSent(item, feedConfig.LastSentTime, window) {
+				continue
+			}
+
+			// 기준이 없는 피드는 나이를 알 수 없는 글도 오래된 글로 본다
+			if !catchupCutoff.IsZero() && (item.PublishedParsed == nil || item.PublishedParsed.Before(catchupCutoff)) {
+				continue
+			}
+
+			// 예약 글이나 임시 글이 잠깐 올라왔다 사라질 수 있으니 충분히 지난 글만 보낸다.
+			// 기다리는 글은 피드 맨 위에 있으므로 기준 글이 이 글을 넘어가지 않아 다음 실행에서 다시 새 글로 잡힌다
+			if minItemAge > 0 && item.PublishedParsed != nil && time.Since(*item.PublishedParsed) < minItemAge {
+				waiting = true
+				if oldestWaiting[i].IsZero() || item.PublishedParsed.Before(oldestWaiting[i]) {
+					oldestWaiting[i] = *item.PublishedParsed
+				}
+				continue
+			}
+
+			// 걸러진 글도 다음 실행에서 다시 검사하지 않도록 중복 확인 기준은 옮겨둔다
+			if skipReason := itemSkipReason(item, feedConfig, channel.LanguageFilter); skipReason != "" {
+				log.Printf("Skipping item %s from feed %s: %s", item.Title, feedConfig.BlogName, skipReason)
+				if len(queues[i]) == 0 && !pointerMoved[i] {
+					channel.Feeds[i].LastPostLink = item.Link
+					pointerMoved[i] = true
+					needsUpdate = true
+				}
+				continue
+			}
+
+			queues[i] = append(queues[i], item)
+		}
+
+		// 기준 글이 사라지고 새 글이 한꺼번에 잡히면 블로그를 옮기면서 링크가 모두 바뀐 경우다.
+		// 예전 글이 쏟아지지 않도록 보내지 않고 기준만 최신 글로 다시 잡는다
+		if feedConfig.LastPostLink != "" && !pointerFound && len(queues[i]) > formatChangeLimit {
+			log.Printf("Feed %s (%s) in channel %s looks re-formatted: %d new items without the last sent link, re-baselining", feedConfig.BlogName, feedConfig.RssURL, channel.ID, len(queues[i]))
+			queues[i] = nil
+			channel.Feeds[i].LastPostLink = feed.Items[0].Link
+			if feed.Items[0].PublishedParsed != nil {
+				channel.Feeds[i].LastSentTime = *feed.Items[0].PublishedParsed
+			}
+			pointerMoved[i] = true
+			needsUpdate = true
+			rebaselinedFeeds = append(rebaselinedFeeds, i)
+			continue
+		}
+
+		// 보낼 글이 없어도 기준을 최신 글로 잡아서 다음 실행부터는 평소처럼 비교한다
+		if feedConfig.LastPostLink == "" && len(queues[i]) == 0 && !pointerMoved[i] && !waiting && len(feed.Items) > 0 {
+			channel.Feeds[i].LastPostLink = feed.Items[0].Link
+			pointerMoved[i] = true
+			needsUpdate = true
+		}
+	}
+
+	postQueues := buildPostQueues(channel.Feeds, queues, channel.DeliveryMode, channel.BurstThreshold)
+	totalPosts := 0
+	for _, postQueue := range postQueues {
+		totalPosts += len(postQueue)
+	}
+	// 전송 한도는 채널 단위로 먼저 가져가서, 한도에 걸리는지 보내기 전에 알 수 있게 한다
+	allowedPosts := budget.take(totalPosts)
+
+	spaced := channel.MinPostInterval > 0
+	incremental := spaced || allowedPosts < totalPosts
+	if incremental {
+		// 최소 전송 간격이나 전송 한도 때문에 남은 글을 미룰 수 있으니 피드마다 오래된 글부터 보내서
+		// 중복 확인 기준이 실제로 보낸 글까지만 옮겨가도록 한다
+		for i := range postQueues {
+			slices.Reverse(postQueues[i])
+		}
+	}
+
+	originalFeeds := slices.Clone(channel.Feeds)
+	lastSentLinks := make([]string, len(channel.Feeds))
+	posts := orderPendingPosts(channel.Feeds, postQueues, sendOrder())
+	mirrorQueues := make(map[string][]mirrorSend)
+	var mirrorChannelIDs []string
+	for postIndex, post := range posts {
+		if postIndex >= allowedPosts {
+			log.Printf("Send budget for this run is used up, deferring %d posts for channel %s to the next run", len(posts)-postIndex, channel.ID)
+			deferPendingPosts(&channel, originalFeeds, lastSentLinks, posts[postIndex:])
+			needsUpdate = true
+			break
+		}
+
+		if spaced && !waitForPostSlot(ctx, channel) {
+			log.Printf("Deferring %d posts for channel %s to the next run (min post interval %ds)", len(posts)-postIndex, channel.ID, channel.MinPostInterval)
+			deferPendingPosts(&channel, originalFeeds, lastSentLinks, posts[postIndex:])
+			needsUpdate = true
+			break
+		}
+
+		feedConfig := channel.Feeds[post.feedIndex]
+		newestItem := post.items[0]
+
+		content := buildPostContent(feedConfig, post, channel.DisplayFields)
+		targetChannelID := feedTargetChannelID(channel, feedConfig)
+
+		// 글 하나짜리 메시지만 임베드로 보낸다. 미리보기를 끈 채널과 /display 로 표시 항목을 고른 채널은 텍스트 형식을 그대로 쓰고,
+		// 임베드 전송이 실패하면 텍스트로 다시 보내므로 실패한 전송 기록에는 텍스트 내용을 남긴다
+		var embed *discordgo.MessageEmbed
+		if len(post.items) == 1 && post.burstTotal == 0 && !channel.SuppressEmbeds && len(channel.DisplayFields) == 0 {
+			embed = buildFeedEmbed(feedConfig, newestItem)
+		}
+
+		// 새로 추가된 피드의 첫 글 앞에는 피드 배너로 구독을 알린다. 스레드 모드에서는 스레드 이름이 그 역할을 한다
+		if !feedConfig.IntroSent && feedConfig.TotalPostsSent == 0 {
+			feed := fetched[post.feedIndex].feed
+			if feed.Image != nil && feed.Image.URL != "" && !(channel.ThreadMode && targetChannelID == channel.ID) {
+				if err := sendFeedIntro(ctx, targetChannelID, feedConfig.BlogName, feed.Image.URL); err != nil {
+					failureLog.record("feed intros failed", targetChannelID, err)
+				}
+			}
+			// 소개는 한 번만 시도한다. 실패해도 다음 글마다 다시 보내지 않는다
+			channel.Feeds[post.feedIndex].IntroSent = true
+			needsUpdate = true
+		}
+
+		var err error
+		if channel.ThreadMode && targetChannelID == channel.ID {
+			threadID := feedConfig.ThreadID
+			err = sendFeedThreadMessage(ctx, channel.ID, &channel.Feeds[post.feedIndex], embed, content, channel.SuppressEmbeds)
+			if channel.Feeds[post.feedIndex].ThreadID != threadID {
+				needsUpdate = true
+			}
+		} else {
+			_, err = sendFeedPost(ctx, targetChannelID, embed, content, channel.SuppressEmbeds)
+		}
+		if err != nil {
+			failureLog.record("Discord messages failed", targetChannelID, err)
+			failedSends = append(failedSends, newFailedSend(targetChannelID, feedConfig, newestItem, content, channel.SuppressEmbeds, err))
+			continue
+		}
+
+		// 미러 채널에는 같은 글을 그대로 보내고, 중복 확인 기준은 이 채널에만 남긴다
+		for _, mirrorChannelID := range feedConfig.MirrorChannelIDs {
+			if _, ok := mirrorQueues[mirrorChannelID]; !ok {
+				mirrorChannelIDs = append(mirrorChannelIDs, mirrorChannelID)
+			}
+			mirrorQueues[mirrorChannelID] = append(mirrorQueues[mirrorChannelID], mirrorSend{feedConfig: feedConfig, item: newestItem, embed: embed, content: content})
+		}
+
+		if incremental {
+			if spaced {
+				channel.LastChannelPostAt = time.Now()
+			}
+			channel.Feeds[post.feedIndex].LastPostLink = newestItem.Link
+			lastSentLinks[post.feedIndex] = newestItem.Link
+		} else if !pointerMoved[post.feedIndex] {
+			channel.Feeds[post.feedIndex].LastPostLink = newestItem.Link
+			pointerMoved[post.feedIndex] = true
+		}
+		channel.Feeds[post.feedIndex].LastSentTime = sentTimeAfterDelivery(time.Now(), oldestWaiting[post.feedIndex])
+		channel.Feeds[post.feedIndex].TotalPostsSent += len(post.items)
+		channel.Feeds[post.feedIndex].AvgDeliverySeconds = updateAvgDeliverySeconds(channel.Feeds[post.feedIndex].AvgDeliverySeconds, post.items, time.Now())
+
+		channelNewItemsCount += len(post.items)
+		needsUpdate = true
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	failedSends = append(failedSends, sendMirrorQueues(ctx, mirrorChannelIDs, mirrorQueues, channel.SuppressEmbeds)...)
+
+	for _, i := range rebaselinedFeeds {
+		content := fmt.Sprintf("🔄 **%s** 피드 형식이 바뀐 것 같다냥, 다시 맞췄다냥! 이제부터 새로 올라오는 글만 보내준다냥~", channel.Feeds[i].BlogName)
+		targetChannelID := feedTargetChannelID(channel, channel.Feeds[i])
+		if _, err := sendDiscordMessage(ctx, targetChannelID, content, channel.SuppressEmbeds); err != nil {
+			failureLog.record("format change notices failed", targetChannelID, err)
+		}
+	}
+
+	for _, post := range updatedPosts {
+		// 한도에 걸리면 수정 시각을 그대로 둬서 다음 실행에서 보낸다
+		if budget.take(1) == 0 {
+			break
+		}
+
+		item := post.items[0]
+		content := fmt.Sprintf("📝 %s\n**✏️ 수정됨: %s**\n🔗 %s", channel.Feeds[post.feedIndex].BlogName, item.Title, item.Link)
+		targetChannelID := feedTargetChannelID(channel, channel.Feeds[post.feedIndex])
+
+		var err error
+		if channel.ThreadMode && targetChannelID == channel.ID {
+			threadID := channel.Feeds[post.feedIndex].ThreadID
+			err = sendFeedThreadMessage(ctx, channel.ID, &channel.Feeds[post.feedIndex], nil, content, channel.SuppressEmbeds)
+			if channel.Feeds[post.feedIndex].ThreadID != threadID {
+				needsUpdate = true
+			}
+		} else {
+			_, err = sendDiscordMessage(ctx, targetChannelID, content, channel.SuppressEmbeds)
+		}
+		// 보내지 못하면 수정 시각을 그대로 둬서 다음 실행에서 다시 보낸다
+		if err != nil {
+			failureLog.record("update notices failed", targetChannelID, err)
+			continue
+		}
+
+		channel.Feeds[post.feedIndex].LastPostUpdatedAt = *item.UpdatedParsed
+		needsUpdate = true
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	// 중복 확인 기준이 새 글로 옮겨갔거나 아직 수정 시각이 없으면, 기준 글의 수정 시각을 새로 남긴다
+	for i, feedConfig := range channel.Feeds {
+		if fetched[i].feed == nil {
+			continue
+		}
+		if feedConfig.LastPostLink == startingFeeds[i].LastPostLink && !feedConfig.LastPostUpdatedAt.IsZero() {
+			continue
+		}
+		if updatedAt := itemUpdatedAt(fetched[i].feed, feedConfig.LastPostLink); !updatedAt.IsZero() && !updatedAt.Equal(feedConfig.LastPostUpdatedAt) {
+			channel.Feeds[i].LastPostUpdatedAt = updatedAt
+			needsUpdate = true
+		}
+	}
+
+	return channelProcessResult{
+		channel:      channel,
+		newItems:     channelNewItemsCount,
+		needsUpdate:  needsUpdate,
+		failedSends:  failedSends,
+		feedFailures: feedFailures,
+		err:          nil,
+	}
+}
+
+// 한 미러 채널의 메시지는 한 워커가 순서대로 보낸다.
+func sendMirrorQueues(ctx context.Context, mirrorChannelIDs []string, queues map[string][]mirrorSend, suppressEmbeds bool) []FailedSend {
+	targets := make(chan string, len(mirrorChannelIDs))
+	for _, mirrorChannelID := range mirrorChannelIDs {
+		targets <- mirrorChannelID
+	}
+	close(targets)
+
+	var mu sync.Mutex
+	var failedSends []FailedSend
+	var wg sync.WaitGroup
+	for range min(sendConcurrency(), len(mirrorChannelIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for mirrorChannelID := range targets {
+				for _, send := range queues[mirrorChannelID] {
+					if _, err := sendFeedPost(ctx, mirrorChannelID, send.embed, send.content, suppressEmbeds); err != nil {
+						failureLog.record("mirror messages failed", mirrorChannelID, err)
+						mu.Lock()
+						failedSends = append(failedSends, newFailedSend(mirrorChannelID, send.feedConfig, send.item, send.content, suppressEmbeds, err))
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failedSends
+}
+
+// 다른 채널로 보내는 피드도 설정과 중복 확인 기준은 원래 채널 문서에 남는다.
+func feedTargetChannelID(channel DiscordChannel, feedConfig Feed) string {
+	if feedConfig.OverrideChannelID != "" {
+		return feedConfig.OverrideChannelID
+	}
+	return channel.ID
+}
+
+// 기록이 없으면 처음 보는 것이므로 수정으로 보지 않는다.
+func isItemUpdatedSince(item *gofeed.Item, lastUpdatedAt time.Time) bool {
+	return item.UpdatedParsed != nil && !lastUpdatedAt.IsZero() && item.UpdatedParsed.After(lastUpdatedAt)
+}
+
+func itemUpdatedAt(feed *gofeed.Feed, link string) time.Time {
+	for _, item := range feed.Items {
+		if normalizeURL(item.Link) == normalizeURL(link) {
+			if item.UpdatedParsed != nil {
+				return *item.UpdatedParsed
+			}
+			break
+		}
+	}
+	return time.Time{}
+}
+
+func isGloballyPaused(ctx context.Context, client *mongo.Client) bool {
+	if os.Getenv("GLOBAL_PAUSE") == "true" {
+		return true
+	}
+
+	var botConfig BotConfig
+	err := client.Database("feednyang").Collection("bot_config").FindOne(ctx, bson.M{"_id": "global"}).Decode(&botConfig)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to read bot config, assuming not paused: %v", err)
+		}
+		return false
+	}
+
+	return botConfig.Paused
+}
+
+func newFailedSend(channelID string, feedConfig Feed, item *gofeed.Item, content string, suppressEmbeds bool, err error) FailedSend {
+	return FailedSend{
+		ChannelID:      channelID,
+		BlogName:       feedConfig.BlogName,
+		RssURL:         feedConfig.RssURL,
+		ItemTitle:      item.Title,
+		ItemLink:       item.Link,
+		Content:        content,
+		SuppressEmbeds: suppressEmbeds,
+		Error:          err.Error(),
+		FailedAt:       time.Now(),
+	}
+}
+
+// failedAt 의 TTL 인덱스로 FailedSendRetention 이 지나면 MongoDB 가 지운다.
+func saveFailedSends(ctx context.Context, client *mongo.Client, failedSends []FailedSend) error {
+	collection := client.Database("feednyang").Collection("failed_sends")
+
+	// 같은 인덱스가 이미 있으면 아무 일도 하지 않는다
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "failedAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(FailedSendRetention.Seconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create TTL index: %w", err)
+	}
+
+	documents := make([]interface{}, len(failedSends))
+	for i, failedSend := range failedSends {
+		documents[i] = failedSend
+	}
+
+	return withMongoRetry(ctx, func() error {
+		_, err := collection.InsertMany(ctx, documents)
+		return err
+	})
+}
+
+// 여러 채널이 같은 피드를 구독해도 한 줄로 보이도록 피드 URL 별로 묶는다.
+func buildFailureReport(failures []feedFailure) string {
+	type failureGroup struct {
+		feedFailure
+		channels int
+	}
+
+	var groups []*failureGroup
+	groupByURL := make(map[string]*failureGroup)
+	for _, failure := range failures {
+		key := normalizeFeedURL(failure.rssURL)
+		group, ok := groupByURL[key]
+		if !ok {
+			group = &failureGroup{feedFailure: failure}
+			groupByURL[key] = group
+			groups = append(groups, group)
+		}
+		group.channels++
+	}
+
+	slices.SortStableFunc(groups, func(a, b *failureGroup) int {
+		return b.channels - a.channels
+	})
+
+	content := fmt.Sprintf("🚨 **피드 오류 리포트**: 피드 %d개를 가져오지 못했다냥...\n\n", len(groups))
+	for i, group := range groups {
+		line := fmt.Sprintf("• **%s** (<%s>) - %d개 채널, %s\n", group.blogName, group.rssURL, group.channels, group.reason)
+		footer := fmt.Sprintf("…외 %d개", len(groups)-i)
+		if utf8.RuneCountInString(content+line+footer) > DiscordMessageLimit {
+			content += footer
+			break
+		}
+		content += line
+	}
+	return content
+}
+
+// 마지막 리포트 뒤로 OperatorReportInterval 이 지나지 않았으면 보내지 않는다.
+func reportFeedFailures(ctx context.Context, client *mongo.Client, failures []feedFailure) {
+	operatorChannelID := os.Getenv("OPERATOR_CHANNEL_ID")
+	if operatorChannelID == "" || len(failures) == 0 {
+		return
+	}
+
+	configCollection := client.Database("feednyang").Collection("bot_config")
+	var botConfig BotConfig
+	err := configCollection.FindOne(ctx, bson.M{"_id": "global"}).Decode(&botConfig)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("Failed to read bot config, skipping error report: %v", err)
+		return
+	}
+	if time.Since(botConfig.LastErrorReportAt) < OperatorReportInterval {
+		log.Printf("Skipping error report for %d failures, last report was at %v", len(failures), botConfig.LastErrorReportAt)
+		return
+	}
+
+	if _, err := sendDiscordMessage(ctx, operatorChannelID, buildFailureReport(failures), true); err != nil {
+		log.Printf("Failed to send error report to operator channel %s: %v", operatorChannelID, err)
+		return
+	}
+
+	_, err = configCollection.UpdateOne(ctx,
+		bson.M{"_id": "global"},
+		bson.M{"$set": bson.M{"lastErrorReportAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Printf("Failed to record error report time: %v", err)
+	}
+}
+
+// 인증서 검증은 ALLOW_INSECURE_TLS=true 일 때만 끈다.
+func newFeedTLSConfig() *tls.Config {
+	if os.Getenv("ALLOW_INSECURE_TLS") == "true" {
+		log.Printf("WARNING: ALLOW_INSECURE_TLS is set, feed requests skip TLS certificate verification")
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+func newFeedParser() *gofeed.Parser {
+	proxyConfig := httpproxy.FromEnvironment()
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           feedProxy(proxyConfig),
+			DialContext:     feedDialContext(proxyConfig),
+			TLSClientConfig: newFeedTLSConfig(),
+		},
+		// 리다이렉트로 다른 스킴에 접근하지 못하도록 이동할 주소도 검사한다
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return validateFeedURL(req.URL.String())
+		},
+	}
+
+	fp := gofeed.NewParser()
+	fp.Client = httpClient
+	fp.UserAgent = defaultUserAgent()
+	fp.RSSTranslator = &ttlRSSTranslator{}
+
+	return fp
+}
+
+func fetchAndProcessFeeds(ctx context.Context, client *mongo.Client) (int, error) {
+	if isGloballyPaused(ctx, client) {
+		return 0, errGlobalPaused
+	}
+	defer failureLog.flush()
+
+	fp := newFeedParser()
+	channelCollection := client.Database("feednyang").Collection("discord_channels")
+
+	err := ensureDefaultChannels(ctx, channelCollection, fp)
+	if err != nil {
+		log.Printf("Failed to ensure default channels: %v", err)
+	}
+
+	totalNewItemsCount := 0
+
+	var channels []DiscordChannel
+	err = withMongoRetry(ctx, func() error {
+		cursor, err := channelCollection.Find(ctx, bson.M{})
+		if err != nil {
+			return fmt.Errorf("failed to find channels: %w", err)
+		}
+		defer cursor.Close(ctx)
+
+		if err = cursor.All(ctx, &channels); err != nil {
+			return fmt.Errorf("failed to decode channels: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// 채널 목록을 읽지 못하면 아무 피드도 처리할 수 없으므로 피드 처리 오류와 구분한다
+		return totalNewItemsCount, fmt.Errorf("%w: %w", errMongoUnavailable, err)
+	}
+
+	fetched := fetchAllFeeds(ctx, fp, channels)
+	budget := &sendBudget{remaining: maxSendsPerRun()}
+
+	// 채널마다 고루틴 하나가 전송을 맡으므로 한 채널 안의 전송은 항상 순서대로 이루어진다
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, channelWorkerCount())
+	results := make(chan channelProcessResult, len(channels))
+
+	for i, channel := range channels {
+		wg.Add(1)
+		go func(ch DiscordChannel, feeds []feedFetchResult) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			ch, feeds, ok := refreshChannel(ctx, channelCollection, fp, ch, feeds)
+			if !ok {
+				return
+			}
+
+			// processChannelFeeds 가 피드 슬라이스를 고치므로 비교할 원본은 복사해 둔다
+			original := ch
+			original.Feeds = slices.Clone(ch.Feeds)
+			result := processChannelFeeds(ctx, ch, feeds, budget)
+			result.original = original
+			results <- result
+		}(channel, fetched[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var feedFailures []feedFailure
+	for result := range results {
+		feedFailures = append(feedFailures, result.feedFailures...)
+
+		if result.err != nil {
+			log.Printf("Error processing channel %s: %v", result.channel.ID, result.err)
+			continue
+		}
+
+		if result.needsUpdate {
+			result.channel.UpdatedAt = time.Now()
+			err = saveChannelProgress(ctx, channelCollection, result.original, result.channel)
+			if err != nil {
+				log.Printf("Failed to update channel document for %s: %v", result.channel.ID, err)
+			}
+		}
+
+		if len(result.failedSends) > 0 {
+			if err := saveFailedSends(ctx, client, result.failedSends); err != nil {
+				log.Printf("Failed to save %d failed sends for channel %s: %v", len(result.failedSends), result.channel.ID, err)
+			}
+		}
+
+		totalNewItemsCount += result.newItems
+		log.Printf("Processed %d new items for channel %s", result.newItems, result.channel.ID)
+	}
+
+	reportFeedFailures(ctx, client, feedFailures)
+
+	return totalNewItemsCount, nil
+}
+
+// Extended JSON 으로 써야 날짜 같은 BSON 타입이 그대로 되살아난다.
+func writeChannelsNDJSON(ctx context.Context, channelCollection *mongo.Collection, writer io.Writer) (int, error) {
+	cursor, err := channelCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find channels: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		line, err := bson.MarshalExtJSON(cursor.Current, false, false)
+		if err != nil {
+			return count, fmt.Errorf("failed to encode channel %d: %w", count+1, err)
+		}
+		if _, err := writer.Write(append(line, '\n')); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, cursor.Err()
+}
+
+// 채널이 많아도 메모리에 올리지 않도록 커서에서 읽는 대로 업로드한다.
+func exportAllChannels(ctx context.Context, channelCollection *mongo.Collection, bucket string) (string, int, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	key := fmt.Sprintf("%s/discord_channels-%s.ndjson", ExportKeyPrefix, time.Now().UTC().Format("20060102T150405Z"))
+	reader, writer := io.Pipe()
+	type exportResult struct {
+		count int
+		err   error
+	}
+	exported := make(chan exportResult, 1)
+	go func() {
+		count, err := writeChannelsNDJSON(ctx, channelCollection, writer)
+		writer.CloseWithError(err)
+		exported <- exportResult{count: count, err: err}
+	}()
+
+	uploader := manager.NewUploader(s3.NewFromConfig(cfg))
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        reader,
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	// 업로드가 중간에 끝나도 쓰는 쪽이 막히지 않도록 읽는 쪽을 닫는다
+	reader.CloseWithError(err)
+	result := <-exported
+	if result.err != nil {
+		return "", result.count, fmt.Errorf("failed to export channels: %w", result.err)
+	}
+	if err != nil {
+		return "", result.count, fmt.Errorf("failed to upload export to s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return key, result.count, nil
+}
+
+func validateImportedChannel(channel DiscordChannel) error {
+	if channel.ID == "" {
+		return errors.New("missing _id")
+	}
+	if channel.BurstThreshold != 0 && (channel.BurstThreshold < BurstPreviewCount || channel.BurstThreshold > MaxBurstThreshold) {
+		return fmt.Errorf("burstThreshold %d is out of range", channel.BurstThreshold)
+	}
+	for i, feed := range channel.Feeds {
+		if feed.RssURL == "" || feed.BlogName == "" {
+			return fmt.Errorf("feed %d is missing rssUrl or blogName", i+1)
+		}
+	}
+	return nil
+}
+
+// 채널 설정과 이미 있는 피드는 그대로 둔다.
+func mergeImportedChannel(ctx context.Context, channelCollection *mongo.Collection, imported DiscordChannel, document bson.Raw) error {
+	var channel DiscordChannel
+	err := channelCollection.FindOne(ctx, bson.M{"_id": imported.ID}).Decode(&channel)
+	if err == mongo.ErrNoDocuments {
+		_, err = channelCollection.InsertOne(ctx, document)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(channel.Feeds))
+	for _, feed := range channel.Feeds {
+		existing[normalizeFeedURL(feed.RssURL)] = true
+	}
+
+	var missingFeeds []Feed
+	for _, feed := range imported.Feeds {
+		if existing[normalizeFeedURL(feed.RssURL)] {
+			continue
+		}
+		missingFeeds = append(missingFeeds, feed)
+		existing[normalizeFeedURL(feed.RssURL)] = true
+	}
+	if len(missingFeeds) == 0 {
+		return nil
+	}
+
+	return pushFeeds(ctx, channelCollection, channel.ID, missingFeeds)
+}
+
+// 형식이 맞지 않거나 쓰기에 실패한 줄은 세고 다음 줄을 계속 처리한다.
+func importAllChannels(ctx context.Context, channelCollection *mongo.Collection, bucket string, key string, mode string) (ImportResult, error) {
+	var result ImportResult
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	output, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+	defer output.Body.Close()
+
+	scanner := bufio.NewScanner(output.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxImportLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var document bson.Raw
+		var channel DiscordChannel
+		if err := bson.UnmarshalExtJSON(line, false, &document); err != nil {
+			log.Printf("Skipping import line %d: %v", lineNumber, err)
+			result.Skipped++
+			continue
+		}
+		if err := bson.Unmarshal(document, &channel); err != nil {
+			log.Printf("Skipping import line %d: %v", lineNumber, err)
+			result.Skipped++
+			continue
+		}
+		if err := validateImportedChannel(channel); err != nil {
+			log.Printf("Skipping import line %d: %v", lineNumber, err)
+			result.Skipped++
+			continue
+		}
+
+		if mode == ImportModeReplace {
+			err = withMongoRetry(ctx, func() error {
+				_, err := channelCollection.ReplaceOne(ctx, bson.M{"_id": channel.ID}, document, options.Replace().SetUpsert(true))
+				return err
+			})
+		} else {
+			err = mergeImportedChannel(ctx, channelCollection, channel, document)
+		}
+		if err != nil {
+			log.Printf("Failed to import channel %s (line %d): %v", channel.ID, lineNumber, err)
+			result.Failed++
+			continue
+		}
+		result.Imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read import line %d: %w", lineNumber+1, err)
+	}
+
+	return result, nil
+}
+
+// CloudWatch Embedded Metric Format 로그라서 SDK 호출 없이 알람을 걸 수 있다.
+func emitMongoUnavailableMetric(stage string, err error) {
+	metric := map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": time.Now().UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{{
+				"Namespace":  MetricNamespace,
+				"Dimensions": [][]string{{}},
+				"Metrics":    []map[string]string{{"Name": "MongoUnavailable", "Unit": "Count"}},
+			}},
+		},
+		"MongoUnavailable": 1,
+		"stage":            stage,
+		"error":            err.Error(),
+	}
+
+	line, marshalErr := json.Marshal(metric)
+	if marshalErr != nil {
+		log.Printf("Failed to marshal MongoDB unavailable metric: %v", marshalErr)
+		return
+	}
+	// log 패키지의 시각 접두사가 붙으면 EMF 로 인식되지 않으므로 표준 출력에 그대로 쓴다
+	fmt.Println(string(line))
+}
+
+func handleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, error) {
+	client, err := connectMongoDB(ctx)
+	if err != nil {
+		emitMongoUnavailableMetric("connect", err)
 		return LambdaResponse{
-			StatusCode: 500,
-			Body:       fmt.Sprintf("Failed to connect to MongoDB: %v", err),
-		}, err
+			StatusCode: 503,
+			Body:       fmt.Sprintf("MongoDB is unavailable: %v", err),
+		}, fmt.Errorf("%w: %w", errMongoUnavailable, err)
+	}
+
+	if event.DetailType == DetailTypeSyncDefaultFeeds {
+		channelCollection := client.Database("feednyang").Collection("discord_channels")
+		addedCounts, err := syncDefaultFeeds(ctx, channelCollection, newFeedParser())
+		if err != nil {
+			log.Printf("Failed to sync default feeds: %v", err)
+			statusCode := 500
+			if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+				emitMongoUnavailableMetric("sync-default-feeds", err)
+				statusCode = 503
+			}
+			return LambdaResponse{
+				StatusCode: statusCode,
+				Body:       fmt.Sprintf("Failed to sync default feeds: %v", err),
+			}, err
+		}
+
+		body, _ := json.Marshal(addedCounts)
+		return LambdaResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf("Synced default feeds into %d channels: %s", len(addedCounts), body),
+		}, nil
+	}
+
+	if event.DetailType == DetailTypeExportAll {
+		bucket := os.Getenv("BACKUP_BUCKET")
+		if bucket == "" {
+			return LambdaResponse{
+				StatusCode: 400,
+				Body:       "BACKUP_BUCKET is not configured",
+			}, nil
+		}
+
+		channelCollection := client.Database("feednyang").Collection("discord_channels")
+		key, count, err := exportAllChannels(ctx, channelCollection, bucket)
+		if err != nil {
+			log.Printf("Failed to export channels: %v", err)
+			return LambdaResponse{
+				StatusCode: 500,
+				Body:       fmt.Sprintf("Failed to export channels: %v", err),
+			}, err
+		}
+
+		log.Printf("Exported %d channels to s3://%s/%s", count, bucket, key)
+		return LambdaResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf("Exported %d channels to s3://%s/%s", count, bucket, key),
+		}, nil
+	}
+
+	if event.DetailType == DetailTypeImportAll {
+		bucket := os.Getenv("BACKUP_BUCKET")
+		if bucket == "" {
+			return LambdaResponse{
+				StatusCode: 400,
+				Body:       "BACKUP_BUCKET is not configured",
+			}, nil
+		}
+
+		var detail ImportAllDetail
+		rawDetail, _ := json.Marshal(event.Detail)
+		if err := json.Unmarshal(rawDetail, &detail); err != nil || detail.Key == "" {
+			return LambdaResponse{
+				StatusCode: 400,
+				Body:       `import-all needs detail {"key": "<export key>", "mode": "merge" | "replace"}`,
+			}, nil
+		}
+		if detail.Mode == "" {
+			detail.Mode = ImportModeMerge
+		}
+		if detail.Mode != ImportModeMerge && detail.Mode != ImportModeReplace {
+			return LambdaResponse{
+				StatusCode: 400,
+				Body:       fmt.Sprintf("Unknown import mode %q (merge | replace)", detail.Mode),
+			}, nil
+		}
+		if detail.Mode == ImportModeReplace && detail.Confirm != ImportReplaceConfirmation {
+			return LambdaResponse{
+				StatusCode: 400,
+				Body:       fmt.Sprintf("replace mode overwrites live channels; set detail.confirm to %q to proceed", ImportReplaceConfirmation),
+			}, nil
+		}
+
+		channelCollection := client.Database("feednyang").Collection("discord_channels")
+		result, err := importAllChannels(ctx, channelCollection, bucket, detail.Key, detail.Mode)
+		body, _ := json.Marshal(result)
+		if err != nil {
+			log.Printf("Failed to import channels from %s: %v (%s)", detail.Key, err, body)
+			return LambdaResponse{
+				StatusCode: 500,
+				Body:       fmt.Sprintf("Failed to import channels: %v %s", err, body),
+			}, err
+		}
+
+		log.Printf("Imported channels from s3://%s/%s in %s mode: %s", bucket, detail.Key, detail.Mode, body)
+		return LambdaResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf("Imported channels from s3://%s/%s in %s mode: %s", bucket, detail.Key, detail.Mode, body),
+		}, nil
 	}
-	defer client.Disconnect(ctx)
 
 	totalNewItemsCount, err := fetchAndProcessFeeds(ctx, client)
+	if errors.Is(err, errGlobalPaused) {
+		log.Println("Posting is globally paused, skipping this run")
+		return LambdaResponse{
+			StatusCode: 200,
+			Body:       "Posting is globally paused",
+		}, nil
+	}
+	if errors.Is(err, errMongoUnavailable) {
+		emitMongoUnavailableMetric("find-channels", err)
+		return LambdaResponse{
+			StatusCode: 503,
+			Body:       err.Error(),
+		}, err
+	}
 	if err != nil {
 		return LambdaResponse{
 			StatusCode: 500,
@@ -394,5 +3212,8 @@ func handleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 }
 
 func main() {
+	log.Printf("Using %d feed workers and %d channel workers (%d vCPUs)", feedWorkerCount(), channelWorkerCount(), runtime.NumCPU())
+	feedRetryAttempts, feedRetryBaseDelay = loadFeedRetryConfig()
+	log.Printf("Fetching feeds with up to %d attempts (base retry delay %v)", feedRetryAttempts, feedRetryBaseDelay)
 	lambda.Start(handleRequest)
 }
@@ -0,0 +1,752 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/text/encoding/korean"
+)
+
+func TestWaitingItemSurvivesSendOfAnotherItem(t *testing.T) {
+	minItemAge := 60 * time.Minute
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	waitingPublished := now.Add(-10 * time.Minute)
+	waitingItem := &gofeed.Item{Link: "https://example.com/waiting", PublishedParsed: &waitingPublished}
+
+	// 같은 피드의 다른 글이 이번 실행에서 먼저 나갔다
+	lastSentTime := sentTimeAfterDelivery(now, waitingPublished)
+
+	// 최소 나이를 채운 다음 실행에서 기다리던 글이 더는 기다리지 않으니, 지나간 글로 걸러지지도 않아야 한다
+	nextRun := now.Add(minItemAge)
+	if nextRun.Sub(waitingPublished) < minItemAge {
+		t.Fatalf("waiting item should be old enough at %v", nextRun)
+	}
+	if isBeforeLastSent(waitingItem, lastSentTime, DefaultGraceWindow) {
+		t.Errorf("waiting item published at %v was dropped by last sent time %v", waitingPublished, lastSentTime)
+	}
+}
+
+func TestSentTimeAfterDeliveryWithoutWaitingItems(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if got := sentTimeAfterDelivery(now, time.Time{}); !got.Equal(now) {
+		t.Errorf("sentTimeAfterDelivery() = %v, want %v", got, now)
+	}
+}
+
+func TestNormalizeFeedURLMatchesDefaultFeeds(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   string
+		defaulted string
+	}{
+		{"scheme", "http://d2.naver.com/d2.atom", "https://d2.naver.com/d2.atom"},
+		{"host case", "https://D2.naver.com/d2.atom", "https://d2.naver.com/d2.atom"},
+		{"trailing slash", "https://d2.naver.com/d2.atom/", "https://d2.naver.com/d2.atom"},
+		{"tracking query", "https://medium.com/feed/daangn?utm_source=x", "https://medium.com/feed/daangn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := normalizeFeedURL(tt.channel), normalizeFeedURL(tt.defaulted); got != want {
+				t.Errorf("normalizeFeedURL(%q) = %q, want %q", tt.channel, got, want)
+			}
+		})
+	}
+}
+
+func TestItemSkipReasonFilterKeywords(t *testing.T) {
+	feedConfig := Feed{BlogName: "blog", Keywords: []string{"kafka"}}
+
+	tests := []struct {
+		title    string
+		wantSent bool
+	}{
+		{"Kafka tuning", true},
+		{"UI redesign", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			item := &gofeed.Item{Title: tt.title}
+			reason := itemSkipReason(item, feedConfig, "")
+			if sent := reason == ""; sent != tt.wantSent {
+				t.Errorf("itemSkipReason(%q) = %q, want sent = %v", tt.title, reason, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestSplitForDiscord(t *testing.T) {
+	line := strings.Repeat("가", 50)
+	var lines []string
+	for range 100 {
+		lines = append(lines, line)
+	}
+	multiline := strings.Join(lines, "\n")
+	unbreakable := strings.Repeat("a", 3000)
+
+	tests := []struct {
+		name       string
+		content    string
+		wantChunks int
+		// rejoin 은 나눈 조각을 원래 내용으로 되돌리는 구분자다
+		rejoin string
+	}{
+		{"just under the limit", strings.Repeat("a", 1999), 1, ""},
+		{"5000 characters over many lines", multiline, 3, "\n"},
+		{"unbreakable 3000 character line", unbreakable, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitForDiscord(tt.content)
+			if len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			for i, chunk := range chunks {
+				if length := utf8.RuneCountInString(chunk); length > DiscordMessageLimit {
+					t.Errorf("chunk %d has %d characters, over the limit %d", i, length, DiscordMessageLimit)
+				}
+			}
+			if got := strings.Join(chunks, tt.rejoin); got != tt.content {
+				t.Errorf("chunks do not add back up to the original content")
+			}
+		})
+	}
+}
+
+func TestNewFeedParserInsecureTLS(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"unset", "", false},
+		{"set", "true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALLOW_INSECURE_TLS", tt.value)
+			transport := newFeedParser().Client.Transport.(*http.Transport)
+			if got := transport.TLSClientConfig.InsecureSkipVerify; got != tt.want {
+				t.Errorf("InsecureSkipVerify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDiscordSession 은 보낸 메시지를 기록만 하는 가짜 세션이다.
+type fakeDiscordSession struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (s *fakeDiscordSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, data.Content)
+	return &discordgo.Message{ChannelID: channelID, Content: data.Content}, nil
+}
+
+func (s *fakeDiscordSession) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, embed.Title)
+	return &discordgo.Message{ChannelID: channelID}, nil
+}
+
+func (s *fakeDiscordSession) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: messageID, Name: data.Name}, nil
+}
+
+func TestDiscordSessionIsReusedAcrossSends(t *testing.T) {
+	t.Setenv("DISCORD_BOT_TOKEN_SECRET_ARN", "")
+	t.Setenv("DISCORD_BOT_TOKEN", "test-token")
+
+	fake := &fakeDiscordSession{}
+	builds := 0
+	originalNewDiscordSession := newDiscordSession
+	newDiscordSession = func(botToken string) (discordSender, error) {
+		builds++
+		return fake, nil
+	}
+	discordSession, discordSessionToken = nil, ""
+	t.Cleanup(func() {
+		newDiscordSession = originalNewDiscordSession
+		discordSession, discordSessionToken = nil, ""
+	})
+
+	const messages = 5
+	for range messages {
+		if _, err := sendDiscordMessage(context.Background(), "channel", "새 글이다냥", false); err != nil {
+			t.Fatalf("sendDiscordMessage() error = %v", err)
+		}
+	}
+	if _, err := sendDiscordEmbed(context.Background(), "channel", &discordgo.MessageEmbed{Title: "임베드"}); err != nil {
+		t.Fatalf("sendDiscordEmbed() error = %v", err)
+	}
+
+	if builds != 1 {
+		t.Errorf("built %d sessions for %d sends, want 1", builds, messages+1)
+	}
+	if len(fake.sent) != messages+1 {
+		t.Errorf("fake session got %d sends, want %d", len(fake.sent), messages+1)
+	}
+}
+
+func TestConnectMongoDBReusesClient(t *testing.T) {
+	t.Setenv("MONGODB_URI_SECRET_ARN", "")
+	t.Setenv("MONGODB_URI", "mongodb://localhost:27017")
+
+	pings := 0
+	originalPingMongoDB := pingMongoDB
+	pingMongoDB = func(ctx context.Context, client *mongo.Client) error {
+		pings++
+		return nil
+	}
+	cachedMongoClient = nil
+	mongoClientSuspect = false
+	t.Cleanup(func() {
+		pingMongoDB = originalPingMongoDB
+		if cachedMongoClient != nil {
+			cachedMongoClient.Disconnect(context.Background())
+			cachedMongoClient = nil
+		}
+		mongoClientSuspect = false
+	})
+
+	first, err := connectMongoDB(context.Background())
+	if err != nil {
+		t.Fatalf("first connectMongoDB() error = %v", err)
+	}
+	second, err := connectMongoDB(context.Background())
+	if err != nil {
+		t.Fatalf("second connectMongoDB() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("connectMongoDB() returned different clients %p and %p", first, second)
+	}
+	if pings != 1 {
+		t.Errorf("pinged %d times, want 1 (only when connecting)", pings)
+	}
+
+	markMongoClientSuspect()
+	third, err := connectMongoDB(context.Background())
+	if err != nil {
+		t.Fatalf("connectMongoDB() after a failed operation error = %v", err)
+	}
+	if third != first {
+		t.Errorf("connectMongoDB() replaced a client that still answers pings")
+	}
+	if pings != 2 {
+		t.Errorf("pinged %d times, want 2 (once more after a failed operation)", pings)
+	}
+	if _, err := connectMongoDB(context.Background()); err != nil {
+		t.Fatalf("connectMongoDB() error = %v", err)
+	}
+	if pings != 2 {
+		t.Errorf("pinged %d times, want the healthy client to stop being pinged", pings)
+	}
+}
+
+func TestCleanTitleDoubleEncodedFeed(t *testing.T) {
+	fixture := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>blog</title>
+<item>
+<title><![CDATA[&amp;lt;b&amp;gt;Kafka&amp;lt;/b&amp;gt; 튜닝 &amp;amp; 운영]]></title>
+<link>https://example.com/kafka</link>
+</item>
+<item>
+<title>평범한 제목</title>
+<link>https://example.com/plain</link>
+</item>
+</channel></rss>`
+
+	body, err := decodeFeedBody([]byte(fixture), "application/rss+xml; charset=utf-8")
+	if err != nil {
+		t.Fatalf("decodeFeedBody() error = %v", err)
+	}
+	feed, err := gofeed.NewParser().ParseString(string(body))
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	want := []string{"Kafka 튜닝 & 운영", "평범한 제목"}
+	for i, item := range feed.Items {
+		if got := cleanTitle(item.Title); got != want[i] {
+			t.Errorf("cleanTitle(%q) = %q, want %q", item.Title, got, want[i])
+		}
+	}
+}
+
+func TestDecodeFeedBodyEUCKR(t *testing.T) {
+	fixture := `<?xml version="1.0" encoding="EUC-KR"?>
+<rss version="2.0"><channel><title>기술 블로그</title>
+<item><title>카프카 튜닝기</title><link>https://example.com/kafka</link></item>
+</channel></rss>`
+	encoded, err := korean.EUCKR.NewEncoder().String(fixture)
+	if err != nil {
+		t.Fatalf("failed to encode fixture: %v", err)
+	}
+
+	body, err := decodeFeedBody([]byte(encoded), "application/rss+xml")
+	if err != nil {
+		t.Fatalf("decodeFeedBody() error = %v", err)
+	}
+	feed, err := gofeed.NewParser().ParseString(string(body))
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+
+	if feed.Title != "기술 블로그" {
+		t.Errorf("feed title = %q, want %q", feed.Title, "기술 블로그")
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Title != "카프카 튜닝기" {
+		t.Errorf("items = %+v, want one item titled %q", feed.Items, "카프카 튜닝기")
+	}
+}
+
+func TestGraceWindowBoundary(t *testing.T) {
+	t.Setenv("FEED_GRACE_WINDOW", "10m")
+	window := graceWindow()
+	if window != 10*time.Minute {
+		t.Fatalf("graceWindow() = %v, want 10m", window)
+	}
+
+	lastSentTime := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		offset  time.Duration
+		wantOld bool
+	}{
+		{"just inside", -window + time.Second, false},
+		{"on the boundary", -window, false},
+		{"just outside", -window - time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			published := lastSentTime.Add(tt.offset)
+			item := &gofeed.Item{PublishedParsed: &published}
+			if got := isBeforeLastSent(item, lastSentTime, window); got != tt.wantOld {
+				t.Errorf("isBeforeLastSent(published %v) = %v, want %v", published, got, tt.wantOld)
+			}
+		})
+	}
+}
+
+func TestNormalizeURLMediumSource(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{"medium rss source", "https://medium.com/daangn/post-1a2b?source=rss----f107b03c406e---4", "https://medium.com/daangn/post-1a2b"},
+		{"rss source with other params", "https://medium.com/p/1a2b?source=rss-abc&page=2", "https://medium.com/p/1a2b?page=2"},
+		{"utm params", "https://example.com/post?utm_source=rss&utm_medium=feed", "https://example.com/post"},
+		{"non-rss source is kept", "https://example.com/post?source=newsletter", "https://example.com/post?source=newsletter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeURL(tt.link); got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.link, got, tt.want)
+			}
+		})
+	}
+
+	if normalizeFeedURL("https://medium.com/feed/daangn?source=rss----f107b03c406e---4") != normalizeFeedURL("https://medium.com/feed/daangn") {
+		t.Errorf("normalizeFeedURL() kept the Medium source parameter")
+	}
+}
+
+func TestNewFeedParserProxy(t *testing.T) {
+	proxy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake proxy: %v", err)
+	}
+	defer proxy.Close()
+	other, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start private listener: %v", err)
+	}
+	defer other.Close()
+
+	t.Setenv("ALLOW_PRIVATE_FEED_TARGETS", "")
+	for _, key := range []string{"HTTP_PROXY", "http_proxy", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("HTTPS_PROXY", "http://"+proxy.Addr().String())
+	transport := newFeedParser().Client.Transport.(*http.Transport)
+
+	t.Run("public target goes through the proxy", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "https://1.1.1.1/feed", nil)
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy() error = %v", err)
+		}
+		if proxyURL == nil || proxyURL.Host != proxy.Addr().String() {
+			t.Errorf("Proxy() = %v, want %s", proxyURL, proxy.Addr())
+		}
+	})
+
+	t.Run("private target is refused before reaching the proxy", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "https://10.0.0.1/feed", nil)
+		if _, err := transport.Proxy(req); err == nil {
+			t.Errorf("Proxy() allowed a private target")
+		}
+	})
+
+	t.Run("proxy on a private address can be dialed", func(t *testing.T) {
+		conn, err := transport.DialContext(context.Background(), "tcp", proxy.Addr().String())
+		if err != nil {
+			t.Fatalf("DialContext(proxy) error = %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("other private addresses are still refused", func(t *testing.T) {
+		conn, err := transport.DialContext(context.Background(), "tcp", other.Addr().String())
+		if err == nil {
+			conn.Close()
+			t.Errorf("DialContext() connected to a private address that is not the proxy")
+		}
+	})
+}
+
+func TestBuildPostQueuesBurstBelowPreviewCount(t *testing.T) {
+	items := []*gofeed.Item{{Title: "1"}, {Title: "2"}, {Title: "3"}}
+	postQueues := buildPostQueues([]Feed{{BlogName: "냥"}}, [][]*gofeed.Item{items}, "", 2)
+	if len(postQueues[0]) != 1 {
+		t.Fatalf("got %d posts, want one burst post", len(postQueues[0]))
+	}
+	if post := postQueues[0][0]; len(post.items) != 3 || post.burstTotal != 3 {
+		t.Errorf("burst post has %d items and total %d, want 3 and 3", len(post.items), post.burstTotal)
+	}
+}
+
+func TestValidateImportedChannelBurstThreshold(t *testing.T) {
+	for threshold, wantErr := range map[int]bool{0: false, 2: true, BurstPreviewCount: false, MaxBurstThreshold: false, MaxBurstThreshold + 1: true} {
+		err := validateImportedChannel(DiscordChannel{ID: "c", BurstThreshold: threshold})
+		if (err != nil) != wantErr {
+			t.Errorf("validateImportedChannel(burstThreshold=%d) error = %v, want error %v", threshold, err, wantErr)
+		}
+	}
+}
+
+func TestUpdateAvgDeliverySeconds(t *testing.T) {
+	sentAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	published := func(ago time.Duration) *gofeed.Item {
+		publishedAt := sentAt.Add(-ago)
+		return &gofeed.Item{PublishedParsed: &publishedAt}
+	}
+
+	if got := updateAvgDeliverySeconds(0, []*gofeed.Item{published(10 * time.Minute)}, sentAt); got != 600 {
+		t.Fatalf("first delivery = %d, want 600", got)
+	}
+	if got := updateAvgDeliverySeconds(600, []*gofeed.Item{published(2 * time.Minute)}, sentAt); got != 480 {
+		t.Errorf("updateAvgDeliverySeconds(600, 2m) = %d, want 480", got)
+	}
+	if got := updateAvgDeliverySeconds(600, []*gofeed.Item{{}}, sentAt); got != 600 {
+		t.Errorf("an item without a publish time moved the average to %d", got)
+	}
+	if got := updateAvgDeliverySeconds(0, []*gofeed.Item{published(-time.Minute)}, sentAt); got != 0 {
+		t.Errorf("a future publish time gave %d, want 0", got)
+	}
+}
+
+func TestUpdateAvgParseMsIgnoresJitter(t *testing.T) {
+	avg := updateAvgParseMs(0, 1230*time.Millisecond)
+	if avg != 1200 {
+		t.Fatalf("first measurement = %d, want 1200", avg)
+	}
+
+	for _, elapsed := range []time.Duration{1250 * time.Millisecond, 1180 * time.Millisecond, 1210 * time.Millisecond} {
+		if got := updateAvgParseMs(avg, elapsed); got != avg {
+			t.Errorf("updateAvgParseMs(%d, %v) = %d, want unchanged %d", avg, elapsed, got, avg)
+		}
+	}
+
+	if got := updateAvgParseMs(avg, 5*time.Second); got == avg {
+		t.Errorf("updateAvgParseMs(%d, 5s) did not move the average", avg)
+	}
+}
+
+func TestFetchFeedWithRetryAttempts(t *testing.T) {
+	t.Setenv("ALLOW_PRIVATE_FEED_TARGETS", "true")
+	originalAttempts, originalDelay := feedRetryAttempts, feedRetryBaseDelay
+	feedRetryAttempts, feedRetryBaseDelay = 3, time.Millisecond
+	t.Cleanup(func() { feedRetryAttempts, feedRetryBaseDelay = originalAttempts, originalDelay })
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/private":
+			// 다른 호스트의 로그인 페이지로 보낸다
+			http.Redirect(w, r, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/login", http.StatusFound)
+		case "/login":
+			w.Write([]byte("<html><body>로그인</body></html>"))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantAttempts int
+	}{
+		{"login required stops after one attempt", "/private", 1},
+		{"server error uses every attempt", "/broken", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, attempts, err := fetchFeedWithRetry(context.Background(), newFeedParser(), Feed{BlogName: "blog", RssURL: server.URL + tt.path})
+			if err == nil {
+				t.Fatalf("fetchFeedWithRetry() succeeded, want an error")
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d (err: %v)", attempts, tt.wantAttempts, err)
+			}
+			if loginRequired := errors.Is(err, errFeedLoginRequired); loginRequired != (tt.path == "/private") {
+				t.Errorf("login required = %v for %s (err: %v)", loginRequired, tt.path, err)
+			}
+		})
+	}
+}
+
+// fakeChannelCollection 은 채널 문서 하나에 $set, $unset, $inc, $push, $pull 과 rssUrl arrayFilters 를 적용하는 가짜 컬렉션이다.
+type fakeChannelCollection struct {
+	mu      sync.Mutex
+	channel DiscordChannel
+}
+
+func (c *fakeChannelCollection) UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operations, ok := update.(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("unsupported update %T", update)
+	}
+
+	filterURLs := make(map[string]string)
+	for _, opt := range opts {
+		if opt.ArrayFilters == nil {
+			continue
+		}
+		for _, arrayFilter := range opt.ArrayFilters.Filters {
+			for key, value := range arrayFilter.(bson.M) {
+				identifier, _, _ := strings.Cut(key, ".")
+				filterURLs[identifier] = value.(string)
+			}
+		}
+	}
+
+	document, err := documentFields(c.channel)
+	if err != nil {
+		return nil, err
+	}
+	var feeds []bson.M
+	for _, feed := range c.channel.Feeds {
+		fields, err := documentFields(feed)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, fields)
+	}
+
+	usedIdentifiers := make(map[string]bool)
+	target := func(key string) (bson.M, string, error) {
+		rest, ok := strings.CutPrefix(key, "feeds.$[")
+		if !ok {
+			return document, key, nil
+		}
+		identifier, field, _ := strings.Cut(rest, "].")
+		rssURL, ok := filterURLs[identifier]
+		if !ok {
+			return nil, "", fmt.Errorf("no array filter for identifier %q", identifier)
+		}
+		usedIdentifiers[identifier] = true
+		for _, feed := range feeds {
+			if feed["rssUrl"] == rssURL {
+				return feed, field, nil
+			}
+		}
+		return nil, field, nil
+	}
+
+	for operator, fields := range operations {
+		for key, value := range fields.(bson.M) {
+			switch operator {
+			case "$set", "$unset", "$inc":
+				fieldDocument, field, err := target(key)
+				if err != nil {
+					return nil, err
+				}
+				if fieldDocument == nil {
+					continue
+				}
+				switch operator {
+				case "$set":
+					fieldDocument[field] = value
+				case "$unset":
+					delete(fieldDocument, field)
+				case "$inc":
+					fieldDocument[field] = toInt64(fieldDocument[field]) + toInt64(value)
+				}
+			case "$push":
+				pushed := []Feed{}
+				switch value := value.(type) {
+				case Feed:
+					pushed = append(pushed, value)
+				case bson.M:
+					pushed = value["$each"].([]Feed)
+				}
+				for _, feed := range pushed {
+					fields, err := documentFields(feed)
+					if err != nil {
+						return nil, err
+					}
+					feeds = append(feeds, fields)
+				}
+			case "$pull":
+				rssURL := value.(bson.M)["rssUrl"]
+				feeds = slices.DeleteFunc(feeds, func(feed bson.M) bool { return feed["rssUrl"] == rssURL })
+			default:
+				return nil, fmt.Errorf("unsupported operator %s", operator)
+			}
+		}
+	}
+
+	// MongoDB 는 쓰지 않는 arrayFilters 식별자가 있으면 업데이트를 거부한다
+	for identifier := range filterURLs {
+		if !usedIdentifiers[identifier] {
+			return nil, fmt.Errorf("array filter %q was not used in the update", identifier)
+		}
+	}
+
+	document["feeds"] = feeds
+	data, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var updated DiscordChannel
+	if err := bson.Unmarshal(data, &updated); err != nil {
+		return nil, err
+	}
+	c.channel = updated
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func toInt64(value any) int64 {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case int64:
+		return value
+	}
+	return 0
+}
+
+func TestChannelProgressUpdateTouchesOnlyChangedFeeds(t *testing.T) {
+	before := DiscordChannel{ID: "channel", Feeds: []Feed{
+		{BlogName: "A", RssURL: "https://a.example.com/rss", LastPostLink: "https://a.example.com/1"},
+		{BlogName: "B", RssURL: "https://b.example.com/rss", LastPostLink: "https://b.example.com/1"},
+		{BlogName: "C", RssURL: "https://c.example.com/rss", TotalPostsSent: 4, LastError: FeedErrorFetchFailed},
+	}}
+	after := before
+	after.Feeds = slices.Clone(before.Feeds)
+	after.Feeds[1].LastPostLink = "https://b.example.com/2"
+	after.Feeds[2].TotalPostsSent = 6
+	after.Feeds[2].LastError = ""
+
+	update, arrayFilters, err := channelProgressUpdate(before, after)
+	if err != nil {
+		t.Fatalf("channelProgressUpdate() error = %v", err)
+	}
+
+	want := bson.M{
+		"$set":   bson.M{"feeds.$[f1].lastPostLink": "https://b.example.com/2"},
+		"$unset": bson.M{"feeds.$[f2].lastError": ""},
+		"$inc":   bson.M{"feeds.$[f2].totalPostsSent": 2},
+	}
+	if fmt.Sprint(update) != fmt.Sprint(want) {
+		t.Errorf("update = %v, want %v", update, want)
+	}
+	wantFilters := []any{bson.M{"f1.rssUrl": "https://b.example.com/rss"}, bson.M{"f2.rssUrl": "https://c.example.com/rss"}}
+	if fmt.Sprint(arrayFilters) != fmt.Sprint(wantFilters) {
+		t.Errorf("arrayFilters = %v, want %v", arrayFilters, wantFilters)
+	}
+
+	if update, _, _ := channelProgressUpdate(before, before); update != nil {
+		t.Errorf("unchanged channel produced update %v", update)
+	}
+}
+
+func TestSaveChannelProgressRacesWithAdd(t *testing.T) {
+	lastSentTime := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	feedA := Feed{BlogName: "A", RssURL: "https://a.example.com/rss", LastPostLink: "https://a.example.com/1", TotalPostsSent: 3}
+	feedB := Feed{BlogName: "B", RssURL: "https://b.example.com/rss"}
+
+	for run := range 20 {
+		collection := &fakeChannelCollection{channel: DiscordChannel{ID: "channel", Feeds: []Feed{feedA}}}
+
+		// 폴러가 읽은 채널에서 A 의 글 두 개를 보냈다
+		before := collection.channel
+		before.Feeds = slices.Clone(before.Feeds)
+		after := before
+		after.Feeds = slices.Clone(before.Feeds)
+		after.Feeds[0].LastPostLink = "https://a.example.com/3"
+		after.Feeds[0].LastSentTime = lastSentTime
+		after.Feeds[0].TotalPostsSent += 2
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := pushFeeds(context.Background(), collection, "channel", []Feed{feedB}); err != nil {
+				t.Errorf("pushFeeds() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := saveChannelProgress(context.Background(), collection, before, after); err != nil {
+				t.Errorf("saveChannelProgress() error = %v", err)
+			}
+		}()
+		wg.Wait()
+
+		feeds := collection.channel.Feeds
+		if len(feeds) != 2 || feeds[1].RssURL != feedB.RssURL {
+			t.Fatalf("run %d: feeds = %+v, want A and the added B", run, feeds)
+		}
+		if feeds[0].TotalPostsSent != 5 {
+			t.Errorf("run %d: totalPostsSent = %d, want 5", run, feeds[0].TotalPostsSent)
+		}
+		if feeds[0].LastPostLink != "https://a.example.com/3" || !feeds[0].LastSentTime.Equal(lastSentTime) {
+			t.Errorf("run %d: read position = %q at %v, want %q at %v", run, feeds[0].LastPostLink, feeds[0].LastSentTime, "https://a.example.com/3", lastSentTime)
+		}
+	}
+}